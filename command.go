@@ -0,0 +1,106 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	. "gobit/internal/config"
+	"gobit/internal/db"
+	"gobit/internal/util"
+	"io"
+	"os"
+)
+
+// querycmd - Flags and store shared by the subcommands reading the stored
+// events, trades and outcomes
+type querycmd struct {
+	flags  *flag.FlagSet
+	query  db.Query
+	from   string
+	to     string
+	output string
+}
+
+// newquerycmd - Subcommand flag set with the -from and -to time range flags,
+// fromago and toago are the example durations of their usage
+func newquerycmd(name, fromago, toago string) *querycmd {
+	c := &querycmd{flags: flag.NewFlagSet(name, flag.ContinueOnError)}
+	c.flags.StringVar(&c.from, "from", "", "start time, RFC3339, date or duration ago (e.g. "+fromago+")")
+	c.flags.StringVar(&c.to, "to", "", "end time, RFC3339, date or duration ago (e.g. "+toago+")")
+	return c
+}
+
+// filterflags - Adds the symbol, base, quote and notice query filters
+func (c *querycmd) filterflags(notice string) {
+	c.flags.StringVar(&c.query.Symbol, "symbol", "", "pair symbol, e.g. BTCUSDT or BTC/USDT")
+	c.flags.StringVar(&c.query.Base, "base", "", "base asset, e.g. BTC")
+	c.flags.StringVar(&c.query.Quote, "quote", "", "quote asset, e.g. USDT")
+	c.flags.StringVar(&c.query.Notice, "notice", "", notice)
+}
+
+// outputflag - Adds the -o output file flag
+func (c *querycmd) outputflag() {
+	c.flags.StringVar(&c.output, "o", "", "output file, stdout when empty")
+}
+
+// parse returns false when the flags or the time range are invalid
+func (c *querycmd) parse(args []string) bool {
+	if err := c.flags.Parse(args); err != nil {
+		return false
+	}
+	var err error
+	if c.query.From, err = util.ParseTime(c.from); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid -from time "+err.Error())
+		return false
+	}
+	if c.query.To, err = util.ParseTime(c.to); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid -to time "+err.Error())
+		return false
+	}
+	return true
+}
+
+// open returns the event store, warning that an in-memory sqlite database
+// only has its last snapshot on disk
+func (c *querycmd) open() (db.Store, bool) {
+	if Conf.Db.InMemory && Conf.Db.Driver == db.Sqlite {
+		fmt.Fprintln(os.Stderr, "Warning: in-memory database enabled, using its last snapshot")
+	}
+	eventdb, err := db.OpenDb(Storagepath + "/event.db")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to open database "+err.Error())
+		return nil, false
+	}
+	return eventdb, true
+}
+
+// writer returns the -o output file, or stdout when empty, and its close
+func (c *querycmd) writer() (io.Writer, func(), bool) {
+	if c.output == "" {
+		return os.Stdout, func() {}, true
+	}
+	f, err := os.Create(c.output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to create output file "+err.Error())
+		return nil, nil, false
+	}
+	return f, func() { f.Close() }, true
+}
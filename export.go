@@ -0,0 +1,79 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package main
+
+import (
+	"fmt"
+	"gobit/internal/export"
+	"os"
+)
+
+// exportcmd - gobit export subcommand, dumps events and trades tables
+func exportcmd(args []string) int {
+	c := newquerycmd("export", "30m", "5m")
+	table := c.flags.String("table", "events", "table to export: events or trades")
+	format := c.flags.String("format", export.CSV, "output format: csv, jsonl or parquet")
+	c.outputflag()
+	c.filterflags("notice type, e.g. PRICE_CHANGE (events only)")
+	if !c.parse(args) {
+		return 2
+	}
+	if !export.ValidFormat(*format) {
+		fmt.Fprintln(os.Stderr, "Unknown export format "+*format)
+		return 2
+	}
+
+	eventdb, ok := c.open()
+	if !ok {
+		return 1
+	}
+	defer eventdb.Close()
+
+	w, closer, ok := c.writer()
+	if !ok {
+		return 1
+	}
+	defer closer()
+
+	switch *table {
+	case "events":
+		events, err := eventdb.SelectEvents(c.query)
+		if err == nil {
+			err = export.WriteEvents(w, *format, events)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error exporting events "+err.Error())
+			return 1
+		}
+	case "trades":
+		trades, err := eventdb.SelectTrades(c.query)
+		if err == nil {
+			err = export.WriteTrades(w, *format, trades)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error exporting trades "+err.Error())
+			return 1
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "Unknown table "+*table)
+		return 2
+	}
+	return 0
+}
@@ -7,11 +7,13 @@ require (
 	github.com/gdamore/tcell/v2 v2.6.0
 	github.com/jinzhu/configor v1.2.1
 	github.com/kr/pretty v0.1.0 // indirect
+	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/pkg/browser v0.0.0-20210911075715-681adbf594b8
 	github.com/rivo/tview v0.0.0-20230406072732-e22ce9588bb4
 	github.com/rivo/uniseg v0.4.4 // indirect
 	github.com/shibukawa/configdir v0.0.0-20170330084843-e180dbdc8da0
+	github.com/xitongsys/parquet-go v1.6.2
 	golang.org/x/net v0.9.0
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -28,8 +28,11 @@ import (
 	"gobit/internal/ui"
 	"gobit/internal/util"
 	"log"
+	"math"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"golang.org/x/net/websocket"
@@ -39,6 +42,18 @@ import (
 )
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(exportcmd(os.Args[2:]))
+		case "stats":
+			os.Exit(statscmd(os.Args[2:]))
+		case "summary":
+			os.Exit(summarycmd(os.Args[2:]))
+		}
+	}
+
 	// Event Channels msg and control
 	cws := make(chan string)
 	cwc := make(chan bool)
@@ -49,13 +64,33 @@ func main() {
 	twtx := make(chan binance.SubChannelMsg)
 
 	// Placeholder vars
+	session := time.Now()
 	symbolstats := make(map[string]binance.Ticker)
 	symbolinfo := make(map[string]data.Symbol)
 	quotafilter := ""
 	basefilter := ""
 	detailstablesymbol := ""
+	detailsnote := ""
 	var percentfilter float32
-	var tradestats data.TradeStat
+	tradestats := data.NewTradeStats(TrendSpan(), Conf.Trades.Adaptive.Samples)
+	trendwindow := 0
+	trendview := ui.TrendAggregate
+	sweeps := data.NewSweeps(Conf.Trades.Sweep.Window, Conf.Trades.Sweep.PriceWindow)
+	composite := data.NewComposite(Conf.Pump.Window, Conf.Pump.Score, Conf.Pump.MinSignals)
+	popularity := data.NewPopularityRanks(Interval(Conf.Db.SamplePeriod), Conf.TickerTimer)
+	popularityview := data.PopularityAssets
+	profilerange := 0
+	var profilerequests ui.ProfileRequests
+	breadth := data.NewBreadth(BreadthSpan())
+	vols := data.NewVolatilities()
+	indicators := data.NewIndicatorSet()
+	indicatorevents := make(chan binance.Event)
+	noticerates := data.NewNoticeRates(Conf.NoticeRate.Window, Conf.NoticeRate.Factor, Conf.NoticeRate.MinNotices)
+	// Live statistics of the details widget at the selected trend window
+	detailsources := func() ui.DetailSources {
+		return ui.DetailSources{TradeStats: tradestats, Volatilities: vols,
+			Indicators: indicators, Window: TrendWindows[trendwindow]}
+	}
 
 	// Optional log file is stored in the local cache folder
 	if Conf.DisableLogging == false {
@@ -63,11 +98,24 @@ func main() {
 		log.SetOutput(logfile)
 	}
 
+	// Database Init
+	eventdb, err := db.InitDb(Storagepath + "/event.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer eventdb.Close()
+	util.SeedPopularity(popularity, eventdb)
+	util.SeedBreadth(breadth, eventdb)
+	util.SeedNoticeRates(noticerates, eventdb)
+
 	// TUI init
 	app := tview.NewApplication()
 	pages := tview.NewPages()
 	grid := tview.NewGrid()
 
+	// Volume Profile Widget
+	volumeprofile := ui.InitVolumeProfile()
+
 	// LiveFeed Widget
 	livefeed := ui.InitLiveFeed()
 	livefeed.SetDoneFunc(func(key tcell.Key) {
@@ -108,6 +156,46 @@ func main() {
 			}
 		case 'U':
 			ui.DisplayUnSubscribeAllModal(twtx, pages)
+		case 'a':
+			if r, c := livefeed.GetSelectable(); r == true && c == true {
+				row, col := livefeed.GetSelection()
+				symbol := strings.Replace(livefeed.GetCell(row, col).Text, "/", "", 1)
+				anchor, ok := ui.EventTime(livefeed, row)
+				if pair := tradestats.Pair(symbol); row != 0 && ok && pair != nil {
+					pair.Anchor(anchor)
+					detailstablesymbol = livefeed.GetCell(row, col).Text
+					detailsnote = ""
+				}
+			}
+		case 'e':
+			symbol := ""
+			if r, c := livefeed.GetSelectable(); r == true && c == true {
+				row, col := livefeed.GetSelection()
+				if row != 0 {
+					symbol = livefeed.GetCell(row, col).Text
+				}
+			}
+			ui.DisplayThresholdForm(pages, symbol)
+		case 'b':
+			ui.DisplayHistoryPage(app, pages, eventdb)
+		case 's':
+			ui.DisplayStatsPage(app, pages, eventdb)
+		case 'c':
+			ui.DisplayCoMovementPage(app, pages, eventdb)
+		case 'r':
+			ui.DisplayStrengthPage(app, pages, popularity)
+		case 'S':
+			ui.DisplaySummaryPage(app, pages, eventdb, session)
+		case 't':
+			trendwindow = (trendwindow + 1) % len(TrendWindows)
+		case 'T':
+			trendview = (trendview + 1) % ui.TrendViews
+		case 'p':
+			popularityview = (popularityview + 1) % data.PopularityViews
+		case 'v':
+			profilerange = (profilerange + 1) % len(ProfileRanges)
+			go ui.UpdateVolumeProfile(app, volumeprofile, eventdb, detailstablesymbol, ProfileRanges[profilerange],
+				&profilerequests, profilerequests.Next())
 		case 'h':
 			ui.DisplayHelpModal(pages)
 		case 'H':
@@ -119,6 +207,9 @@ func main() {
 	// Trend bar textview widget
 	trendbar := ui.InitTrendBar()
 
+	// Market breadth gauge widget
+	breadthgauge := ui.InitBreadthGauge()
+
 	// Momentum Table Widget
 	momentumtable := ui.InitMomentumTable()
 
@@ -128,18 +219,23 @@ func main() {
 	livefeed.SetSelectedFunc(func(row, column int) {
 		cell := livefeed.GetCell(row, column)
 		detailstablesymbol = cell.Text
-		ui.UpdateDetailTable(cell.Text, detailstable, symbolstats)
+		detailsnote = ui.EventDetails(livefeed, row)
+		ui.UpdateDetailTable(cell.Text, detailsnote, detailstable, symbolstats, detailsources())
+		go ui.UpdateVolumeProfile(app, volumeprofile, eventdb, cell.Text, ProfileRanges[profilerange],
+			&profilerequests, profilerequests.Next())
 	})
 
 	// GUI Grid Layout
-	grid.SetRows(9, 3, 0).
-		SetColumns(-3, -2)
+	grid.SetRows(12, 3, 0).
+		SetColumns(-3, -2, -2)
 
 	// Add items to grid
 	grid.AddItem(momentumtable, 0, 0, 1, 1, 0, 0, false)
 	grid.AddItem(detailstable, 0, 1, 1, 1, 0, 0, false)
+	grid.AddItem(volumeprofile, 0, 2, 1, 1, 0, 0, false)
 	grid.AddItem(trendbar, 1, 0, 1, 2, 0, 0, false)
-	grid.AddItem(livefeed, 2, 0, 1, 2, 2, 10, true)
+	grid.AddItem(breadthgauge, 1, 2, 1, 1, 0, 0, false)
+	grid.AddItem(livefeed, 2, 0, 1, 3, 2, 10, true)
 
 	// Add grid to pages
 	pages.AddPage("grid", grid, true, true)
@@ -149,13 +245,6 @@ func main() {
 		SetFocus(grid).
 		EnableMouse(true)
 
-	// Database Init
-	eventdb, err := db.InitDb(Storagepath + "/event.db")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer eventdb.Close()
-
 	// WebSocket Connections
 	// Trade Abnormal Events WebSocket Connection
 	go binance.AbnormalEventsWSConn(cwc, cws)
@@ -180,12 +269,47 @@ func main() {
 		momentumtable.SetTextAlign(tview.AlignCenter)
 		momentumtable.SetText(data.Messages["notenoughdata"])
 		detailstable.SetText(data.Messages["details"])
+		volumeprofile.SetText(data.Messages["volumeprofile"])
 
 		livefeed.SetCell(0, 0, tview.NewTableCell(data.Messages["waitingfordata"]).
 			SetTextColor(tcell.ColorYellow).
 			SetSelectable(false).
 			SetAlign(tview.AlignCenter))
 
+		// Scores the signals of base assets into suspected pumps and dumps
+		suspect := func(base string, signal data.Signal) {
+			if !Conf.Pump.Enabled || base == "" {
+				return
+			}
+			if c := composite.Add(base, signal); c != nil {
+				ev := util.CompositeNotice(*c)
+				err := eventdb.InsertEvent(ev)
+				if err != nil {
+					log.Println("Error inserting composite event into db " + err.Error())
+				}
+				ui.PrintEvent(livefeed, symbolstats, ev, eventdb)
+				popularity.Add(util.NoticeActivity(ev, symbolstats), time.Now())
+			}
+		}
+
+		// Closes whale sweeps without new trades
+		sweeptimer := time.NewTicker(time.Second)
+		defer sweeptimer.Stop()
+		printsweeps := func(closed ...*data.Sweep) {
+			for _, sweep := range closed {
+				if notional, ok := util.FilterSweep(sweep, symbolinfo, symbolstats, tradestats); ok {
+					ui.PrintSweep(livefeed, symbolstats, symbolinfo, *sweep, notional)
+					info := symbolinfo[sweep.Symbol]
+					popularity.Add(util.TradeActivity(info, true, sweep.IsMaker, sweep.Quantity, notional), time.Now())
+					suspect(info.BaseAsset, util.TradeSignal(sweep.IsMaker, true, info.QuoteAsset))
+				}
+			}
+		}
+
+		// Watches the volatility regime of subscribed pairs every minute
+		voltimer := time.NewTicker(time.Minute)
+		defer voltimer.Stop()
+
 		// Feed Event Loop
 		for {
 			select {
@@ -195,17 +319,38 @@ func main() {
 				if err != nil {
 					log.Println("Error parsing msg " + err.Error())
 				}
+				ev.ReceiveTimestamp = binance.Now()
+				util.TrackLatency("notices", ev.ReceiveTimestamp, ev.Data.SendTimestamp)
 				filter := util.Filter{
 					Quote:   quotafilter,
 					Base:    basefilter,
 					Percent: percentfilter,
 				}
 				if util.FilterEvent(ev, filter) {
-					err := db.InsertDbEvent(ev, eventdb)
+					err := eventdb.InsertEvent(ev)
 					if err != nil {
 						log.Println("Error inserting event into db " + err.Error())
 					}
 					ui.PrintEvent(livefeed, symbolstats, ev, eventdb)
+					// Breadth counts the stored notices, the ones SeedBreadth loads
+					breadth.Add(time.Now(), ev.Data.Symbol, data.BreadthNotice(ev.Data.NoticeType, ev.Data.EventType))
+					popularity.Add(util.NoticeActivity(ev, symbolstats), time.Now())
+					// Notice rate spikes of the stored notices base asset
+					if data.RateNotice(ev.Data.NoticeType) {
+						noticerates.Add(time.Now(), data.Symbol{Symbol: ev.Data.Symbol,
+							BaseAsset: ev.Data.BaseAsset, QuoteAsset: ev.Data.QuotaAsset})
+						if rate, spike := noticerates.Check(ev.Data.BaseAsset, time.Now()); spike {
+							spikeev := util.NoticeRateEvent(rate)
+							err := eventdb.InsertEvent(spikeev)
+							if err != nil {
+								log.Println("Error inserting notice rate event into db " + err.Error())
+							}
+							ui.PrintEvent(livefeed, symbolstats, spikeev, eventdb)
+						}
+					}
+				}
+				if signal, ok := util.NoticeSignal(ev); ok {
+					suspect(ev.Data.BaseAsset, signal)
 				}
 			// Event WebSocket Control
 			case control := <-cwc:
@@ -214,37 +359,104 @@ func main() {
 				}
 			// Trades WebSocket Messages
 			case tr := <-tws:
-				if util.FilterTrade(tr, symbolinfo, symbolstats, &tradestats) {
-					err := db.InsertDbTrade(tr, symbolinfo, eventdb)
+				util.TrackLatency(strings.ToLower(tr.Data.Symbol), tr.ReceiveTimestamp, tr.Data.EventTimestamp)
+				if Conf.Volatility.Enabled && vols.Add(tr.Data.Symbol, time.Now(), tr.Data.Price) {
+					go util.LoadVolatility(vols.Pair(tr.Data.Symbol), tr.Data.Symbol)
+				}
+				// Distance from the VWAP before FilterTrade adds the trade to it
+				sigmas := math.NaN()
+				if pair := tradestats.Pair(tr.Data.Symbol); pair != nil {
+					if distance, ok := pair.Sigmas(tr.Data.Price); ok {
+						sigmas = distance
+					}
+				}
+				if util.FilterTrade(tr, symbolinfo, symbolstats, tradestats) {
+					err := eventdb.InsertTrade(tr, symbolinfo)
 					if err != nil {
 						log.Println("Error inserting trade into db " + err.Error())
 					}
-					ui.PrintTrade(livefeed, symbolstats, symbolinfo, tr, eventdb)
+					ui.PrintTrade(livefeed, symbolstats, symbolinfo, tr, eventdb, sigmas)
+					info := symbolinfo[tr.Data.Symbol]
+					notional := tr.Data.Price * tr.Data.Quantity * util.Rate(info.QuoteAsset, symbolstats)
+					popularity.Add(util.TradeActivity(info, false, tr.Data.IsMaker, tr.Data.Quantity, notional), time.Now())
+					suspect(info.BaseAsset, util.TradeSignal(tr.Data.IsMaker, false, info.QuoteAsset))
+				} else if Conf.Trades.Sweep.Enabled && tr.Data.EventType == "aggTrade" {
+					// Cluster the split trades below the threshold, large trades
+					// are reported on their own
+					printsweeps(sweeps.Add(tr.Data.Symbol, tr.Data.IsMaker, tr.Data.Price,
+						tr.Data.Quantity, tr.Data.TradeTimestamp, tr.ReceiveTimestamp))
 				}
 			// Trades WebSocket Control
 			case tcontrol := <-twc:
 				if tcontrol {
 					break
 				}
+			// Whale Sweeps
+			case <-sweeptimer.C:
+				printsweeps(sweeps.Expire(binance.Now())...)
+			// Technical indicator signals of the watchlist
+			case ev := <-indicatorevents:
+				err := eventdb.InsertEvent(ev)
+				if err != nil {
+					log.Println("Error inserting indicator event into db " + err.Error())
+				}
+				ui.PrintEvent(livefeed, symbolstats, ev, eventdb)
+			// Volatility regime changes
+			case <-voltimer.C:
+				for _, symbol := range vols.Symbols() {
+					v := vols.Pair(symbol)
+					if v == nil || symbolinfo[symbol].Symbol == "" {
+						continue
+					}
+					if cone, regime := v.Regime(VolatilityWindows); regime != 0 {
+						ev := util.VolatilityNotice(symbolinfo[symbol], cone, regime)
+						err := eventdb.InsertEvent(ev)
+						if err != nil {
+							log.Println("Error inserting volatility event into db " + err.Error())
+						}
+						ui.PrintEvent(livefeed, symbolstats, ev, eventdb)
+					}
+				}
 			}
 
 			// Redraw App
 			app.QueueUpdateDraw(func() {
-				_, _, trendbarwidth, _ := trendbar.GetInnerRect()
-				_, _, momentumtablewidth, _ := momentumtable.GetInnerRect()
-				if text := ui.UpdateTrendBar(trendbarwidth, &tradestats); text != "" {
-					// Update TrendBar Title with subscriptions
-					trendbartitle := ""
-					if len(subscriptions) > 0 {
-						trendbartitle = " (" + strings.ToUpper(strings.Join(subscriptions, " ")) + ")"
+				// Update LiveFeed Title with clock offset and stream latencies
+				util.ForgetLatency(func(stream string) bool {
+					if stream == "notices" {
+						return true
+					}
+					for _, s := range subscriptions {
+						if strings.ToLower(s) == stream {
+							return true
+						}
+					}
+					return false
+				})
+				livefeed.SetTitle("Live Feed (" + util.LatencySummary() + ")")
+
+				// Drop trade statistics of unsubscribed pairs
+				subscribed := func(symbol string) bool {
+					for _, s := range subscriptions {
+						if strings.EqualFold(s, symbol) {
+							return true
+						}
 					}
-					trendbar.SetTitle("Trade Trend" + trendbartitle)
-					trendbar.SetText(text)
+					return false
 				}
-				if text := ui.PrintMomentumTable(momentumtablewidth, db.AssetMomentum(eventdb)); text != "" {
-					momentumtable.SetTextAlign(tview.AlignRight)
-					momentumtable.SetText(text)
+				tradestats.Retain(subscribed)
+				vols.Retain(subscribed)
+				// Update TrendBar and fit the grid row to the view
+				trendrows := ui.UpdateTrendView(trendbar, tradestats, trendview,
+					TrendWindows[trendwindow], detailstablesymbol, subscriptions)
+				grid.SetRows(12, trendrows+2, 0)
+				ui.UpdateBreadthGauge(breadthgauge, breadth)
+
+				// Update Details with the live order flow
+				if detailstablesymbol != "" {
+					ui.UpdateDetailTable(detailstablesymbol, detailsnote, detailstable, symbolstats, detailsources())
 				}
+				ui.UpdateMomentumTable(momentumtable, popularity, noticerates, popularityview)
 			})
 			// Warn on small terminals
 			if !ui.CheckTermSizeModal(pages) {
@@ -254,16 +466,80 @@ func main() {
 	}()
 
 	// Periodically fetch asset pairs prices and volumes
+	// and estimate the clock offset to the exchange
 	go func() {
 		for {
+			if err := binance.SyncClock(); err != nil {
+				log.Println("Error syncing clock " + err.Error())
+			}
+			// Refresh the quote conversion rates with the last prices
+			if err := binance.UpdateRates(); err != nil {
+				log.Println("Error updating conversion rates " + err.Error())
+			}
 			time.Sleep(Conf.TickerTimer)
 			util.FillSymbolStats(symbolstats, eventdb)
+
+			// Persist the cumulative volume delta of subscribed pairs
+			for _, symbol := range tradestats.Symbols() {
+				if pair := tradestats.Pair(symbol); pair != nil {
+					err := eventdb.InsertCVD(symbol, pair.CumulativeDelta())
+					if err != nil {
+						log.Println("Error inserting cvd into db " + err.Error())
+					}
+				}
+			}
 			if detailstablesymbol != "" {
-				ui.UpdateDetailTable(detailstablesymbol, detailstable, symbolstats)
+				ui.UpdateDetailTable(detailstablesymbol, detailsnote, detailstable, symbolstats, detailsources())
+				go ui.UpdateVolumeProfile(app, volumeprofile, eventdb, detailstablesymbol, ProfileRanges[profilerange],
+					&profilerequests, profilerequests.Next())
 			}
 		}
 	}()
 
+	// Technical indicators of the watchlist klines
+	if len(Conf.Indicators.Watchlist) > 0 {
+		go util.WatchIndicators(indicators, indicatorevents)
+	}
+
+	// Refresh the notice rate baselines from the stored notices
+	go func() {
+		for {
+			time.Sleep(time.Hour)
+			util.NoticeBaseline(noticerates, eventdb)
+		}
+	}()
+
+	// Record the pair prices after the stored notices
+	go func() {
+		for {
+			time.Sleep(time.Minute)
+			if err := util.UpdateOutcomes(eventdb); err != nil {
+				log.Println("Error updating notice outcomes " + err.Error())
+			}
+		}
+	}()
+
+	// Periodically snapshot the in-memory database
+	snapshotter, snapshot := eventdb.(db.Snapshotter)
+	if snapshot && Conf.Db.SnapshotTimer > 0 {
+		go func() {
+			for {
+				time.Sleep(Conf.Db.SnapshotTimer)
+				if err := snapshotter.Snapshot(); err != nil {
+					log.Println("Error saving database snapshot " + err.Error())
+				}
+			}
+		}()
+	}
+
+	// Stop the TUI on termination signals so the database snapshot is saved
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		<-signals
+		app.Stop()
+	}()
+
 	// Run Tui
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
@@ -271,5 +547,11 @@ func main() {
 		fmt.Print("\033\143") // attempt to recover terminal
 	}
 
+	if snapshot {
+		if err := snapshotter.Snapshot(); err != nil {
+			log.Println("Error saving database snapshot " + err.Error())
+		}
+	}
+
 	os.Exit(0)
 }
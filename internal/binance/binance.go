@@ -21,6 +21,7 @@ package binance
 
 import (
 	"encoding/json"
+	"errors"
 	. "gobit/internal/config"
 	"gobit/internal/data"
 	"io"
@@ -28,6 +29,8 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/net/websocket"
 )
@@ -35,8 +38,9 @@ import (
 // Event Message
 // JSON Structure
 type Event struct {
-	Stream string
-	Data   struct {
+	Stream           string
+	ReceiveTimestamp uint64 `json:"-"` // local receive time, epoch ms
+	Data             struct {
 		EventType     string
 		NoticeType    string
 		Symbol        string
@@ -46,6 +50,9 @@ type Event struct {
 		PriceChange   float32 `json:"priceChange"`
 		Period        string
 		SendTimestamp uint64
+		Details       string  `json:"-"` // explanation of composite events
+		Value         float64 `json:"-"` // measure of derived events, like a score or a rate
+		Ratio         float64 `json:"-"` // measure of derived events to its norm
 	}
 }
 
@@ -58,15 +65,17 @@ type Ticker struct {
 	HighPrice             float64 `json:"highPrice,string"`
 	LowPrice              float64 `json:"lowPrice,string"`
 	Volume                float64 `json:"volume,string"`
+	Count                 uint64  `json:"count"`
 }
 
 // Trade Websocket receive
 // JSON Structure
 type Trade struct {
-	Result string
-	ID     uint64
-	Stream string
-	Data   struct {
+	Result           string
+	ID               uint64
+	Stream           string
+	ReceiveTimestamp uint64 `json:"-"` // local receive time, epoch ms
+	Data             struct {
 		EventType      string  `json:"e"`
 		Symbol         string  `json:"s"`
 		Quantity       float64 `json:"q,string"`
@@ -119,6 +128,7 @@ func TradesWSConnReceive(tradesconn *websocket.Conn, twc chan bool, tws chan Tra
 	for {
 		var tmsg Trade
 		err := websocket.JSON.Receive(tradesconn, &tmsg)
+		tmsg.ReceiveTimestamp = Now()
 		if err != nil {
 			if err == io.EOF {
 				log.Println("End of File msg received " + err.Error())
@@ -236,6 +246,7 @@ func WsTradesAPI(tws chan Trade, twc chan bool) (*websocket.Conn, error) {
 		for {
 			var tmsg Trade
 			err := websocket.JSON.Receive(conn, &tmsg)
+			tmsg.ReceiveTimestamp = Now()
 			if err != nil {
 				if err == io.EOF {
 					log.Println("End of File msg received " + err.Error())
@@ -376,3 +387,65 @@ func GetSymbolInfo(s string, m map[string]data.Symbol) error {
 
 	return err
 }
+
+// Local clock offset to the exchange server time in ms
+var clockoffset int64
+
+// Now returns the local time in epoch ms
+func Now() uint64 {
+	return uint64(time.Now().UnixNano() / int64(time.Millisecond))
+}
+
+// GetServerTime returns exchange time in epoch ms
+// Rest API call to get the server time
+func GetServerTime() (uint64, error) {
+	var st struct {
+		ServerTime uint64 `json:"serverTime"`
+	}
+	resp, err := http.Get(Restapiurl + "time")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// parse json response
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if err = json.Unmarshal(body, &st); err != nil {
+		return 0, err
+	}
+	if st.ServerTime == 0 {
+		return 0, errors.New("invalid server time response")
+	}
+	return st.ServerTime, nil
+}
+
+// SyncClock returns error
+// Estimates the local clock offset against the server time,
+// assuming symmetric request and response delays
+func SyncClock() error {
+	sent := Now()
+	servertime, err := GetServerTime()
+	if err != nil {
+		return err
+	}
+	received := Now()
+	atomic.StoreInt64(&clockoffset, int64(servertime)-int64(sent+received)/2)
+	return nil
+}
+
+// ClockOffset returns the server minus local clock offset in ms
+func ClockOffset() int64 {
+	return atomic.LoadInt64(&clockoffset)
+}
+
+// Latency returns the end-to-end latency in ms of a message sent by the
+// exchange at sent and received locally at received
+func Latency(received, sent uint64) int64 {
+	if sent == 0 || received == 0 {
+		return 0
+	}
+	return int64(received) + ClockOffset() - int64(sent)
+}
@@ -0,0 +1,85 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// Kline candlestick of a symbol, times are epoch ms
+type Kline struct {
+	OpenTime  uint64
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+	CloseTime uint64
+}
+
+// GetKlines returns klines and error
+// Rest API call to get the klines of a symbol from start, epoch ms
+func GetKlines(symbol, interval string, start uint64, limit int) ([]Kline, error) {
+	url := Restapiurl + "klines?symbol=" + symbol + "&interval=" + interval +
+		"&limit=" + strconv.Itoa(limit)
+	if start > 0 {
+		url += "&startTime=" + strconv.FormatUint(start, 10)
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// parse json response, klines are arrays of numbers and strings
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("klines request failed " + resp.Status + " " + string(body))
+	}
+	var rows [][]interface{}
+	if err = json.Unmarshal(body, &rows); err != nil {
+		return nil, err
+	}
+	klines := make([]Kline, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 7 {
+			return nil, errors.New("invalid kline")
+		}
+		var k Kline
+		opentime, _ := row[0].(float64)
+		closetime, _ := row[6].(float64)
+		k.OpenTime, k.CloseTime = uint64(opentime), uint64(closetime)
+		for i, field := range []*float64{&k.Open, &k.High, &k.Low, &k.Close, &k.Volume} {
+			text, _ := row[i+1].(string)
+			if *field, err = strconv.ParseFloat(text, 64); err != nil {
+				return nil, err
+			}
+		}
+		klines = append(klines, k)
+	}
+	return klines, nil
+}
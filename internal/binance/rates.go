@@ -0,0 +1,143 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Conversion rates of every asset into the default quote asset
+var rates = struct {
+	sync.RWMutex
+	symbols []data.Symbol
+	rates   map[string]float64
+	paths   map[string][]string
+}{}
+
+// GetExchangeSymbols returns the trading symbols
+// Rest API call to get exchange information of all symbols
+func GetExchangeSymbols() ([]data.Symbol, error) {
+	var info struct {
+		Symbols []struct {
+			data.Symbol
+			Status string `json:"status"`
+		} `json:"symbols"`
+	}
+	resp, err := http.Get(Restapiurl + "exchangeInfo")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// parse json response
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+	symbols := make([]data.Symbol, 0, len(info.Symbols))
+	for _, s := range info.Symbols {
+		if s.Status == "TRADING" {
+			symbols = append(symbols, s.Symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("no trading symbols in exchange info")
+	}
+	return symbols, nil
+}
+
+// GetAllTickers returns the 24h tickers of all symbols
+// Rest API call to get ticker information of all symbol pairs
+func GetAllTickers() ([]Ticker, error) {
+	var tickers []Ticker
+	resp, err := http.Get(Restapiurl + "ticker/24hr")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// parse json response
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, &tickers)
+	return tickers, err
+}
+
+// UpdateRates returns error
+// Rebuilds the conversion rates into the default quote from the last
+// prices, exchange symbols are fetched once
+func UpdateRates() error {
+	rates.RLock()
+	symbols := rates.symbols
+	rates.RUnlock()
+	if symbols == nil {
+		var err error
+		if symbols, err = GetExchangeSymbols(); err != nil {
+			return err
+		}
+	}
+	tickers, err := GetAllTickers()
+	if err != nil {
+		return err
+	}
+
+	bysymbol := make(map[string]Ticker, len(tickers))
+	for _, t := range tickers {
+		bysymbol[t.Name] = t
+	}
+	markets := make([]data.Market, 0, len(symbols))
+	for _, s := range symbols {
+		if t, ok := bysymbol[s.Symbol]; ok {
+			markets = append(markets, data.Market{Base: s.BaseAsset, Quote: s.QuoteAsset, Price: t.LastPrice, Count: t.Count})
+		}
+	}
+	r, p := data.ConversionRates(markets, Conf.Trades.DefaultQuote)
+
+	rates.Lock()
+	rates.symbols, rates.rates, rates.paths = symbols, r, p
+	rates.Unlock()
+	return nil
+}
+
+// Rate returns the rate converting asset into the default quote asset
+func Rate(asset string) (float64, bool) {
+	rates.RLock()
+	defer rates.RUnlock()
+	rate, ok := rates.rates[asset]
+	return rate, ok
+}
+
+// RatePath returns the assets converting asset into the default quote asset
+func RatePath(asset string) []string {
+	rates.RLock()
+	defer rates.RUnlock()
+	return rates.paths[asset]
+}
@@ -22,6 +22,9 @@ package config
 import (
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/configor"
@@ -36,35 +39,242 @@ import (
 //		"EnableMouse":	"true",
 //		"DisableTimer":	"30s",
 //		"DisableLogging":	"false",
+//		"UTC":	"false",
 //		"Db" : {
+//			"Driver": "sqlite3",
+//			"DSN": "",
+//			"Timescale": "false",
 //			"Retention": "1 hours",
-//			"SamplePeriod": "10 minutes"
+//			"SamplePeriod": "10 minutes",
+//			"OutcomeRetention": "30 days",
 //			"InMemory":	 "true",
+//			"SnapshotTimer": "5m"
+//		}
+//		"Popularity" : {
+//			"Rows": 7,
+//			"Weights": {
+//				"PriceChange": 1,
+//				"PriceBreakthrough": 2,
+//				"VolumePrice": 2,
+//				"BlockTrade": 1,
+//				"Composite": 3,
+//				"Trade": 1,
+//				"Sweep": 2
+//			}
+//		}
+//		"Breadth" : {
+//			"Windows": ["5m", "15m", "1h"]
+//		}
+//		"Volatility" : {
+//			"Enabled": "true",
+//			"Windows": ["5m", "15m", "1h", "4h"]
+//		}
+//		"NoticeRate" : {
+//			"Baseline": "24h",
+//			"Window": "15m",
+//			"Factor": 5,
+//			"MinNotices": 4
+//		}
+//		"Indicators" : {
+//			"Watchlist": ["BTCUSDT", "ETHUSDT"],
+//			"Interval": "15m",
+//			"Poll": "1m",
+//			"RSI": {"Period": 14, "Overbought": 70, "Oversold": 30},
+//			"MACD": {"Fast": 12, "Slow": 26, "Signal": 9},
+//			"Bollinger": {"Period": 20, "Width": 2},
+//			"EMA": {"Fast": 9, "Slow": 21}
+//		}
+//		"Profile" : {
+//			"Ranges": ["15m", "1h", "4h", "24h"],
+//			"ValueArea": 70
+//		}
+//		"CoMovement" : {
+//			"Window": "5m",
+//			"MinCount": 2,
+//			"Assets": 12,
+//			"Period": "24h"
+//		}
+//		"Pump" : {
+//			"Enabled": "true",
+//			"Window": "15m",
+//			"Score": 6,
+//			"MinSignals": 2
 //		}
 //		"Trades" : {
 //			"Quotes": ["USDT", "BTC", "BNB", "ETH"],
 //			"DefaultQuote": "USDT",
-//			"Threshhold": 50000
+//			"Threshhold": 50000,
+//			"TrendWindows": ["1m", "5m", "15m"],
+//			"VWAPBands": 2,
+//			"Overrides": {"BTCUSDT": 500000, "*BTC": 2},
+//			"Sweep": {
+//				"Enabled": "true",
+//				"Window": "100ms",
+//				"PriceWindow": 0.2
+//			},
+//			"Adaptive": {
+//				"Enabled": "false",
+//				"Percentile": 99.5,
+//				"ZScore": 3,
+//				"Floor": 10000,
+//				"Ceiling": 1000000,
+//				"Samples": 2000
+//			}
 //		}
 var Conf = struct {
 	BinanceTerminal string        `default:"https://www.binance.com/en/trade/"`
 	EnableMouse     bool          `default:"true"`
 	TickerTimer     time.Duration `default:"30s"`
 	DisableLogging  bool          `default:"false"`
-	Db              struct {      // SQL Syntax
-		Retention    string `default:"1 hours"`
-		SamplePeriod string `default:"10 minutes"`
-		InMemory     bool   `default:"true"`
+	UTC             bool          `default:"false"` // display event times in UTC
+	Db              struct {
+		Driver           string        `default:"sqlite3"` // sqlite3 or postgres
+		DSN              string        `default:""`        // postgres connection string
+		Timescale        bool          `default:"false"`   // use TimescaleDB hypertables
+		Retention        string        `default:"1 hours"` // SQL Syntax
+		SamplePeriod     string        `default:"10 minutes"`
+		OutcomeRetention string        `default:"30 days"` // prices after notices, SQL Syntax
+		InMemory         bool          `default:"true"`
+		SnapshotTimer    time.Duration `default:"5m"` // in-memory snapshot interval, 0 disables
+	}
+	// In-memory ranking of the most active base assets, decaying over
+	// Db.SamplePeriod
+	Popularity struct {
+		Rows    int `default:"7"`
+		Weights struct {
+			PriceChange       float64 `default:"1"`
+			PriceBreakthrough float64 `default:"2"`
+			VolumePrice       float64 `default:"2"`
+			BlockTrade        float64 `default:"1"`
+			Composite         float64 `default:"3"`
+			Trade             float64 `default:"1"` // large trades of subscribed pairs
+			Sweep             float64 `default:"2"`
+		}
+	}
+	// Advancing and declining symbols of all notices
+	Breadth struct {
+		Windows []string `default:"[5m, 15m, 1h]"`
+	}
+	// Realized volatility of subscribed pairs from one minute closes
+	Volatility struct {
+		Enabled bool     `default:"true"`
+		Windows []string `default:"[5m, 15m, 1h, 4h]"` // the shortest is watched for breakouts
+	}
+	// Notices per hour of every base asset against its stored baseline
+	NoticeRate struct {
+		Baseline   time.Duration `default:"24h"` // limited by Db.Retention
+		Window     time.Duration `default:"15m"`
+		Factor     float64       `default:"5"`
+		MinNotices int           `default:"4"`
+	}
+	// Technical indicators of the watched pairs klines
+	Indicators struct {
+		Watchlist []string      `default:"[BTCUSDT, ETHUSDT]"`
+		Interval  string        `default:"15m"` // kline interval
+		Poll      time.Duration `default:"1m"`
+		RSI       struct {
+			Period     int     `default:"14"`
+			Overbought float64 `default:"70"`
+			Oversold   float64 `default:"30"`
+		}
+		MACD struct {
+			Fast   int `default:"12"`
+			Slow   int `default:"26"`
+			Signal int `default:"9"`
+		}
+		Bollinger struct {
+			Period int     `default:"20"`
+			Width  float64 `default:"2"` // standard deviations
+		}
+		EMA struct {
+			Fast int `default:"9"`
+			Slow int `default:"21"`
+		}
+	}
+	// Volume at price of the stored trades of the selected pair
+	Profile struct {
+		Ranges    []string `default:"[15m, 1h, 4h, 24h]"` // cycled ranges
+		ValueArea float64  `default:"70"`                 // percent of volume
+	}
+	// Base assets with same direction price change or volume notices
+	CoMovement struct {
+		Window   time.Duration `default:"5m"`  // notices at most a window apart move together
+		MinCount int           `default:"2"`   // fewest moves made together
+		Assets   int           `default:"12"`  // rows and columns of the heatmap
+		Period   time.Duration `default:"24h"` // stored notices compared, limited by Db.Retention
+	}
+	// Composite pump and dump detector
+	Pump struct {
+		Enabled    bool          `default:"true"`
+		Window     time.Duration `default:"15m"` // sliding window of the signals
+		Score      float64       `default:"6"`   // net score of a suspected pump or dump
+		MinSignals int           `default:"2"`   // distinct kinds of contributing signals
 	}
 	Trades struct {
 		Quotes       []string `default:"[USDT, BTC, BNB, ETH]"`
 		DefaultQuote string   `default:"USDT"`
 		Threshhold   float64  `default:"50000"`
+		TrendWindows []string `default:"[1m, 5m, 15m]"` // maker/taker trend windows
+		VWAPBands    float64  `default:"2"`             // VWAP band width in standard deviations
+		// Thresholds of symbols or quote assets (*QUOTE) in quote asset terms
+		Overrides map[string]float64
+		// Clusters of same side trades, like split large orders
+		Sweep struct {
+			Enabled     bool          `default:"true"`
+			Window      time.Duration `default:"100ms"` // most time between trades
+			PriceWindow float64       `default:"0.2"`   // most price range in percent
+		}
+		// Per symbol thresholds from the distribution of recent trades
+		Adaptive struct {
+			Enabled    bool    `default:"false"`
+			Percentile float64 `default:"99.5"`    // notional percentile, 0 uses ZScore
+			ZScore     float64 `default:"3"`       // deviations above the mean log notional
+			Floor      float64 `default:"10000"`   // in default quote
+			Ceiling    float64 `default:"1000000"` // in default quote
+			Samples    int     `default:"2000"`    // trades kept per symbol
+		}
 	}
 }{}
 
 var Storagepath string
 
+// Configpath config.json loaded, or where it is saved when none exists
+var Configpath string
+
+// TrendWindows parsed Trades.TrendWindows
+var TrendWindows []time.Duration
+
+// BreadthWindows parsed Breadth.Windows
+var BreadthWindows []time.Duration
+
+// VolatilityWindows parsed Volatility.Windows, shortest first
+var VolatilityWindows []time.Duration
+
+// ProfileRanges parsed Profile.Ranges
+var ProfileRanges []time.Duration
+
+// TrendSpan returns the longest trend window
+func TrendSpan() time.Duration {
+	span := time.Duration(0)
+	for _, window := range TrendWindows {
+		if window > span {
+			span = window
+		}
+	}
+	return span
+}
+
+// BreadthSpan returns the longest breadth window
+func BreadthSpan() time.Duration {
+	span := time.Duration(0)
+	for _, window := range BreadthWindows {
+		if window > span {
+			span = window
+		}
+	}
+	return span
+}
+
 func init() {
 	// Configuration Init
 	configdirs := configdir.New(Vendorname, Appname)
@@ -74,7 +284,8 @@ func init() {
 
 	// Load Config and configuration file
 	if localconfig != nil {
-		err := configor.Load(&Conf, localconfig.Path+"/config.json")
+		Configpath = localconfig.Path + "/config.json"
+		err := configor.Load(&Conf, Configpath)
 		if err != nil {
 			log.Fatal(err.Error())
 			os.Exit(1)
@@ -85,8 +296,117 @@ func init() {
 			log.Fatal(err.Error())
 			os.Exit(1)
 		}
+		Configpath = "config.json"
+		if _, err := os.Stat(Configpath); os.IsNotExist(err) {
+			Configpath = configdirs.QueryFolders(configdir.Global)[0].Path + "/config.json"
+		}
 	}
 
 	Storagepath = localcache.Path
 
+	// Override keys are upper case symbols or *QUOTE
+	overrides := make(map[string]float64, len(Conf.Trades.Overrides))
+	for key, threshold := range Conf.Trades.Overrides {
+		overrides[strings.ToUpper(key)] = threshold
+	}
+	Conf.Trades.Overrides = overrides
+
+	// Parse trade trend windows
+	for _, window := range Conf.Trades.TrendWindows {
+		d, err := time.ParseDuration(window)
+		if err != nil || d < time.Second {
+			log.Fatal("Invalid trend window " + window)
+		}
+		TrendWindows = append(TrendWindows, d)
+	}
+	if len(TrendWindows) == 0 {
+		TrendWindows = []time.Duration{5 * time.Minute}
+	}
+
+	// Parse market breadth windows
+	for _, window := range Conf.Breadth.Windows {
+		d, err := time.ParseDuration(window)
+		if err != nil || d < time.Minute {
+			log.Fatal("Invalid breadth window " + window)
+		}
+		BreadthWindows = append(BreadthWindows, d)
+	}
+	if len(BreadthWindows) == 0 {
+		BreadthWindows = []time.Duration{15 * time.Minute}
+	}
+
+	// Parse realized volatility windows
+	for _, window := range Conf.Volatility.Windows {
+		d, err := time.ParseDuration(window)
+		if err != nil || d < 2*time.Minute {
+			log.Fatal("Invalid volatility window " + window)
+		}
+		VolatilityWindows = append(VolatilityWindows, d)
+	}
+	if len(VolatilityWindows) == 0 {
+		VolatilityWindows = []time.Duration{5 * time.Minute, time.Hour}
+	}
+	sort.Slice(VolatilityWindows, func(i, j int) bool {
+		return VolatilityWindows[i] < VolatilityWindows[j]
+	})
+
+	if Conf.NoticeRate.Window <= 0 {
+		log.Fatal("Invalid notice rate window " + Conf.NoticeRate.Window.String())
+	}
+
+	// Watched symbols are upper case and klines intervals of the exchange
+	for i, symbol := range Conf.Indicators.Watchlist {
+		Conf.Indicators.Watchlist[i] = strings.ToUpper(strings.Replace(symbol, "/", "", 1))
+	}
+	intervals := "1m 3m 5m 15m 30m 1h 2h 4h 6h 8h 12h 1d 3d 1w 1M"
+	if !strings.Contains(" "+intervals+" ", " "+Conf.Indicators.Interval+" ") {
+		log.Fatal("Invalid indicators interval " + Conf.Indicators.Interval + ", one of " + intervals)
+	}
+	for _, period := range []int{Conf.Indicators.RSI.Period, Conf.Indicators.MACD.Fast, Conf.Indicators.MACD.Slow,
+		Conf.Indicators.MACD.Signal, Conf.Indicators.Bollinger.Period, Conf.Indicators.EMA.Fast, Conf.Indicators.EMA.Slow} {
+		if period < 1 {
+			log.Fatal("Invalid indicators period " + strconv.Itoa(period))
+		}
+	}
+
+	// Parse volume profile ranges
+	for _, r := range Conf.Profile.Ranges {
+		d, err := time.ParseDuration(r)
+		if err != nil || d < time.Minute {
+			log.Fatal("Invalid volume profile range " + r)
+		}
+		ProfileRanges = append(ProfileRanges, d)
+	}
+	if len(ProfileRanges) == 0 {
+		ProfileRanges = []time.Duration{time.Hour}
+	}
+
+	// Validate SQL Syntax periods
+	for _, interval := range []string{Conf.Db.Retention, Conf.Db.SamplePeriod, Conf.Db.OutcomeRetention} {
+		if Interval(interval) <= 0 {
+			log.Fatal("Invalid period " + interval)
+		}
+	}
+}
+
+// Interval - Converts SQL Syntax periods, like "10 minutes", to a duration,
+// returns zero for invalid periods
+func Interval(period string) time.Duration {
+	units := map[string]time.Duration{
+		"second": time.Second,
+		"minute": time.Minute,
+		"hour":   time.Hour,
+		"day":    24 * time.Hour,
+		"week":   7 * 24 * time.Hour,
+	}
+	fields := strings.Fields(period)
+	if len(fields) != 2 {
+		return 0
+	}
+	n, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	unit := units[strings.TrimSuffix(strings.ToLower(fields[1]), "s")]
+	return time.Duration(n * float64(unit))
 }
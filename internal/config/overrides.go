@@ -0,0 +1,187 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Guards Conf.Trades.Overrides, edited at runtime from the TUI
+var overrideslock sync.RWMutex
+
+// Override returns the threshold override of a symbol or *QUOTE key
+func Override(key string) (float64, bool) {
+	overrideslock.RLock()
+	defer overrideslock.RUnlock()
+	threshold, ok := Conf.Trades.Overrides[strings.ToUpper(key)]
+	return threshold, ok
+}
+
+// HasOverrides returns true when any threshold override is set
+func HasOverrides() bool {
+	overrideslock.RLock()
+	defer overrideslock.RUnlock()
+	return len(Conf.Trades.Overrides) > 0
+}
+
+// OverrideKeys returns the sorted override keys
+func OverrideKeys() []string {
+	overrideslock.RLock()
+	defer overrideslock.RUnlock()
+	keys := make([]string, 0, len(Conf.Trades.Overrides))
+	for key := range Conf.Trades.Overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetOverride - Sets the threshold override of a symbol or *QUOTE key
+func SetOverride(key string, threshold float64) {
+	overrideslock.Lock()
+	defer overrideslock.Unlock()
+	Conf.Trades.Overrides[strings.ToUpper(key)] = threshold
+}
+
+// DeleteOverride - Removes the threshold override of a symbol or *QUOTE key
+func DeleteOverride(key string) {
+	overrideslock.Lock()
+	defer overrideslock.Unlock()
+	delete(Conf.Trades.Overrides, strings.ToUpper(key))
+}
+
+// SaveOverrides - Writes the overrides to config.json, replacing only the
+// Trades.Overrides node and keeping the order of every other key
+func SaveOverrides() error {
+	content, err := os.ReadFile(Configpath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	config, err := decodeobject(content)
+	if err != nil {
+		return err
+	}
+	trades, err := decodeobject(fieldvalue(config, "Trades"))
+	if err != nil {
+		return err
+	}
+
+	overrideslock.RLock()
+	overrides, err := json.Marshal(Conf.Trades.Overrides)
+	overrideslock.RUnlock()
+	if err != nil {
+		return err
+	}
+	trades = setfield(trades, "Overrides", overrides)
+	config = setfield(config, "Trades", encodeobject(trades))
+
+	var indented bytes.Buffer
+	if err = json.Indent(&indented, encodeobject(config), "", "\t"); err != nil {
+		return err
+	}
+	indented.WriteByte('\n')
+
+	if err = os.MkdirAll(filepath.Dir(Configpath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(Configpath, indented.Bytes(), 0644)
+}
+
+// A key of a JSON object, kept in file order
+type jsonfield struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeobject returns the fields of a JSON object in order, none for an
+// empty or null document
+func decodeobject(content []byte) ([]jsonfield, error) {
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 || bytes.Equal(content, []byte("null")) {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object, got %v", token)
+	}
+	var fields []jsonfield
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, jsonfield{key: token.(string), value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// encodeobject returns the compact JSON object of fields
+func encodeobject(fields []jsonfield) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.key)
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(field.value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes()
+}
+
+// fieldvalue returns the value of key, nil when missing
+func fieldvalue(fields []jsonfield, key string) json.RawMessage {
+	for _, field := range fields {
+		if field.key == key {
+			return field.value
+		}
+	}
+	return nil
+}
+
+// setfield replaces the value of key in place, appending it when missing
+func setfield(fields []jsonfield, key string, value json.RawMessage) []jsonfield {
+	for i := range fields {
+		if fields[i].key == key {
+			fields[i].value = value
+			return fields
+		}
+	}
+	return append(fields, jsonfield{key: key, value: value})
+}
@@ -0,0 +1,88 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "missing file",
+			content: "",
+			want:    "{\n\t\"Trades\": {\n\t\t\"Overrides\": {\n\t\t\t\"*BTC\": 2\n\t\t}\n\t}\n}\n",
+		},
+		{
+			name:    "keeps key order",
+			content: `{"Zeta": 1, "Trades": {"Zscore": 3, "Overrides": {"ETHUSDT": 5}, "Alpha": [1, 2]}, "Alpha": "a"}`,
+			want: "{\n\t\"Zeta\": 1,\n\t\"Trades\": {\n\t\t\"Zscore\": 3,\n\t\t\"Overrides\": {\n\t\t\t\"*BTC\": 2\n\t\t},\n" +
+				"\t\t\"Alpha\": [\n\t\t\t1,\n\t\t\t2\n\t\t]\n\t},\n\t\"Alpha\": \"a\"\n}\n",
+		},
+		{
+			name:    "appends trades",
+			content: `{"Zeta": {"B": 1, "A": 2}}`,
+			want:    "{\n\t\"Zeta\": {\n\t\t\"B\": 1,\n\t\t\"A\": 2\n\t},\n\t\"Trades\": {\n\t\t\"Overrides\": {\n\t\t\t\"*BTC\": 2\n\t\t}\n\t}\n}\n",
+		},
+	}
+
+	saved, savedpath := Conf.Trades.Overrides, Configpath
+	defer func() { Conf.Trades.Overrides, Configpath = saved, savedpath }()
+	Conf.Trades.Overrides = map[string]float64{"*BTC": 2}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Configpath = filepath.Join(t.TempDir(), "config.json")
+			if tt.content != "" {
+				if err := os.WriteFile(Configpath, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := SaveOverrides(); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(Configpath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveOverridesInvalid(t *testing.T) {
+	savedpath := Configpath
+	defer func() { Configpath = savedpath }()
+	Configpath = filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(Configpath, []byte(`[1, 2]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveOverrides(); err == nil {
+		t.Error("expected an error for a non object config")
+	}
+}
@@ -0,0 +1,75 @@
+package data
+
+import (
+	"sync"
+	"time"
+)
+
+// breadthnotice directional notice of a symbol
+type breadthnotice struct {
+	Time      time.Time
+	Symbol    string
+	Direction int
+}
+
+// Breadth market wide mood from the directional notices of all symbols
+// Notices are kept for the longest window, symbols with rising notices
+// advance and symbols with falling notices decline
+type Breadth struct {
+	sync.Mutex
+	notices []breadthnotice
+	span    time.Duration
+}
+
+// NewBreadth returns an empty breadth keeping notices for span
+func NewBreadth(span time.Duration) *Breadth {
+	return &Breadth{span: span}
+}
+
+// BreadthNotice returns the direction of price change, breakthrough and
+// volume notices, zero for the others
+func BreadthNotice(noticetype, eventtype string) int {
+	switch noticetype {
+	case "PRICE_CHANGE", "PRICE_BREAKTHROUGH", "VOLUME_PRICE":
+		return Direction(eventtype)
+	}
+	return 0
+}
+
+// Add - Adds a notice of symbol at time t, notices without direction are
+// ignored
+func (b *Breadth) Add(t time.Time, symbol string, direction int) {
+	if direction == 0 || symbol == "" {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.notices = append(b.notices, breadthnotice{Time: t, Symbol: symbol, Direction: direction})
+}
+
+// Count returns the number of advancing and declining symbols during the
+// last window up to now, a symbol with both counts in both
+func (b *Breadth) Count(now time.Time, window time.Duration) (advancing, declining int) {
+	b.Lock()
+	defer b.Unlock()
+	// Drop the notices older than the longest window
+	expired := 0
+	for expired < len(b.notices) && now.Sub(b.notices[expired].Time) > b.span {
+		expired++
+	}
+	b.notices = b.notices[expired:]
+
+	up := make(map[string]bool)
+	down := make(map[string]bool)
+	for _, n := range b.notices {
+		if now.Sub(n.Time) > window {
+			continue
+		}
+		if n.Direction > 0 {
+			up[n.Symbol] = true
+		} else {
+			down[n.Symbol] = true
+		}
+	}
+	return len(up), len(down)
+}
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreadthNotice(t *testing.T) {
+	tests := []struct {
+		noticetype, eventtype string
+		want                  int
+	}{
+		{"PRICE_CHANGE", "UP_2", 1},
+		{"PRICE_CHANGE", "DOWN_1", -1},
+		{"PRICE_BREAKTHROUGH", "DOWN_BREAKTHROUGH", -1},
+		{"VOLUME_PRICE", "HIGH_VOLUME_RISE_3", 1},
+		{"BLOCK_TRADE", "BLOCK_TRADES_BUY", 0},
+		{"COMPOSITE", "PUMP_SUSPECTED", 0},
+	}
+	for _, tt := range tests {
+		if got := BreadthNotice(tt.noticetype, tt.eventtype); got != tt.want {
+			t.Errorf("BreadthNotice(%s, %s) = %d, want %d", tt.noticetype, tt.eventtype, got, tt.want)
+		}
+	}
+}
+
+func TestBreadthCount(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBreadth(time.Hour)
+	for _, n := range []struct {
+		minutes   int
+		symbol    string
+		direction int
+	}{
+		{0, "OLDUSDT", 1},
+		{30, "AUSDT", 1},
+		{50, "AUSDT", 1},
+		{52, "BUSDT", -1},
+		{55, "CUSDT", 1},
+		{56, "CUSDT", -1},
+		{57, "DUSDT", 0},
+		{58, "", 1},
+	} {
+		b.Add(start.Add(time.Duration(n.minutes)*time.Minute), n.symbol, n.direction)
+	}
+	now := start.Add(61 * time.Minute)
+	tests := []struct {
+		window               time.Duration
+		advancing, declining int
+	}{
+		{5 * time.Minute, 0, 1},
+		{10 * time.Minute, 1, 2},
+		{15 * time.Minute, 2, 2},
+		{time.Hour, 2, 2},
+		{2 * time.Hour, 2, 2}, // notices past the span are dropped
+	}
+	for _, tt := range tests {
+		advancing, declining := b.Count(now, tt.window)
+		if advancing != tt.advancing || declining != tt.declining {
+			t.Errorf("Count(%v) = %d/%d, want %d/%d", tt.window, advancing, declining, tt.advancing, tt.declining)
+		}
+	}
+}
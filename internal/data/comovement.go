@@ -0,0 +1,132 @@
+package data
+
+import (
+	"sort"
+	"time"
+)
+
+// CoMovement how often two base assets got same direction price change or
+// volume notices within a window of each other
+// Score is the share of their moves they made together (Jaccard index)
+type CoMovement struct {
+	A        string
+	B        string
+	Together int
+	CountA   int
+	CountB   int
+	Score    float64
+}
+
+// comovespan first and last notice of a move, same direction notices of an
+// asset less than a window apart are one move
+type comovespan struct {
+	From time.Time
+	To   time.Time
+}
+
+// comovetogether - Number of moves of a matched one to one with moves of b
+// less than window apart, both in time order
+func comovetogether(a, b []comovespan, window time.Duration) int {
+	n := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case b[j].From.Sub(a[i].To) > window:
+			i++
+		case a[i].From.Sub(b[j].To) > window:
+			j++
+		default:
+			n++
+			i++
+			j++
+		}
+	}
+	return n
+}
+
+// CoMovements returns the pairs of base assets moving together at least
+// mincount times in events, strongest first. Moves are together when their
+// notices are at most window apart, on a sliding window.
+func CoMovements(events []EventRecord, window time.Duration, mincount int) []CoMovement {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	sorted := make([]EventRecord, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	// Moves of every asset per direction, and their number
+	moves := map[int]map[string][]comovespan{1: {}, -1: {}}
+	counts := make(map[string]int)
+	for _, ev := range sorted {
+		if ev.NoticeType != "PRICE_CHANGE" && ev.NoticeType != "VOLUME_PRICE" {
+			continue
+		}
+		direction := Direction(ev.EventType)
+		if direction == 0 || ev.BaseAsset == "" {
+			continue
+		}
+		spans := moves[direction][ev.BaseAsset]
+		if last := len(spans) - 1; last >= 0 && ev.Timestamp.Sub(spans[last].To) <= window {
+			spans[last].To = ev.Timestamp
+			continue
+		}
+		moves[direction][ev.BaseAsset] = append(spans, comovespan{From: ev.Timestamp, To: ev.Timestamp})
+		counts[ev.BaseAsset]++
+	}
+
+	together := make(map[[2]string]int)
+	for _, assets := range moves {
+		names := make([]string, 0, len(assets))
+		for name := range assets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i := range names {
+			for j := i + 1; j < len(names); j++ {
+				if n := comovetogether(assets[names[i]], assets[names[j]], window); n > 0 {
+					together[[2]string{names[i], names[j]}] += n
+				}
+			}
+		}
+	}
+
+	comovements := make([]CoMovement, 0)
+	for pair, n := range together {
+		if n < mincount {
+			continue
+		}
+		c := CoMovement{A: pair[0], B: pair[1], Together: n,
+			CountA: counts[pair[0]], CountB: counts[pair[1]]}
+		c.Score = float64(n) / float64(c.CountA+c.CountB-n)
+		comovements = append(comovements, c)
+	}
+	sort.Slice(comovements, func(i, j int) bool {
+		a, b := comovements[i], comovements[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Together != b.Together {
+			return a.Together > b.Together
+		}
+		return a.A+a.B < b.A+b.B
+	})
+	return comovements
+}
+
+// CoMovementAssets returns up to n assets of the strongest co-movements,
+// in order of first appearance
+func CoMovementAssets(comovements []CoMovement, n int) []string {
+	assets := make([]string, 0, n)
+	seen := make(map[string]bool)
+	for _, c := range comovements {
+		for _, name := range []string{c.A, c.B} {
+			if len(assets) < n && !seen[name] {
+				seen[name] = true
+				assets = append(assets, name)
+			}
+		}
+	}
+	return assets
+}
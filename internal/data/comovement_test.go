@@ -0,0 +1,108 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCoMovements(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	notice := func(base, eventtype string, minutes, seconds int) EventRecord {
+		return EventRecord{Timestamp: start.Add(time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second),
+			NoticeType: "PRICE_CHANGE", EventType: eventtype, BaseAsset: base}
+	}
+	tests := []struct {
+		name   string
+		events []EventRecord
+		want   []CoMovement
+	}{
+		{
+			// 4:59 and 5:01 fall in different 5 minute buckets but are 2 minutes apart
+			name:   "across bucket edges",
+			events: []EventRecord{notice("A", "UP_1", 4, 59), notice("B", "UP_1", 5, 1)},
+			want:   []CoMovement{{A: "A", B: "B", Together: 1, CountA: 1, CountB: 1, Score: 1}},
+		},
+		{
+			name:   "too far apart",
+			events: []EventRecord{notice("A", "UP_1", 0, 0), notice("B", "UP_1", 5, 1)},
+		},
+		{
+			name:   "opposite directions",
+			events: []EventRecord{notice("A", "UP_1", 0, 0), notice("B", "DOWN_1", 1, 0)},
+		},
+		{
+			// A notices less than a window apart are one move
+			name: "merged moves",
+			events: []EventRecord{notice("A", "UP_1", 0, 0), notice("A", "UP_2", 4, 0), notice("A", "UP_3", 8, 0),
+				notice("B", "UP_1", 12, 0)},
+			want: []CoMovement{{A: "A", B: "B", Together: 1, CountA: 1, CountB: 1, Score: 1}},
+		},
+		{
+			// One to one matching, B moves once against two moves of A
+			name: "jaccard",
+			events: []EventRecord{notice("A", "UP_1", 0, 0), notice("B", "UP_1", 1, 0),
+				notice("A", "DOWN_1", 30, 0), notice("A", "UP_1", 60, 0)},
+			want: []CoMovement{{A: "A", B: "B", Together: 1, CountA: 3, CountB: 1, Score: 1.0 / 3}},
+		},
+		{
+			name: "unsorted and other notices",
+			events: []EventRecord{notice("B", "DOWN_1", 11, 0), notice("A", "DOWN_1", 10, 0),
+				{Timestamp: start.Add(10 * time.Minute), NoticeType: "BLOCK_TRADE", EventType: "BLOCK_TRADES_SELL", BaseAsset: "C"}},
+			want: []CoMovement{{A: "A", B: "B", Together: 1, CountA: 1, CountB: 1, Score: 1}},
+		},
+		{
+			name: "strongest first",
+			events: []EventRecord{notice("A", "UP_1", 0, 0), notice("B", "UP_1", 0, 30), notice("C", "UP_1", 1, 0),
+				notice("C", "UP_1", 30, 0), notice("B", "UP_1", 30, 30)},
+			want: []CoMovement{
+				{A: "B", B: "C", Together: 2, CountA: 2, CountB: 2, Score: 1},
+				{A: "A", B: "B", Together: 1, CountA: 1, CountB: 2, Score: 0.5},
+				{A: "A", B: "C", Together: 1, CountA: 1, CountB: 2, Score: 0.5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CoMovements(tt.events, 5*time.Minute, 1)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CoMovements = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoMovementsMinCount(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []EventRecord{
+		{Timestamp: start, NoticeType: "VOLUME_PRICE", EventType: "HIGH_VOLUME_RISE_1", BaseAsset: "A"},
+		{Timestamp: start.Add(time.Minute), NoticeType: "VOLUME_PRICE", EventType: "HIGH_VOLUME_RISE_1", BaseAsset: "B"},
+	}
+	if got := CoMovements(events, 5*time.Minute, 2); len(got) != 0 {
+		t.Errorf("CoMovements = %+v under the min count", got)
+	}
+	if got := CoMovements(events, 5*time.Minute, 1); len(got) != 1 {
+		t.Errorf("CoMovements = %+v, want one pair", got)
+	}
+}
+
+func TestCoMovementAssets(t *testing.T) {
+	comovements := []CoMovement{{A: "B", B: "C"}, {A: "A", B: "B"}, {A: "A", B: "D"}}
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{0, []string{}},
+		{2, []string{"B", "C"}},
+		{3, []string{"B", "C", "A"}},
+		{9, []string{"B", "C", "A", "D"}},
+	}
+	for _, tt := range tests {
+		if got := CoMovementAssets(comovements, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CoMovementAssets(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Signal notice or trade flow evidence of a base asset move,
+// positive scores are bullish
+type Signal struct {
+	Time  time.Time
+	Kind  string // notice type or trade flow, like PRICE_CHANGE or TRADE
+	Label string
+	Score float64
+	Quote string
+}
+
+// CompositeEvent scored pump or dump suspicion of a base asset
+type CompositeEvent struct {
+	Base    string
+	Quote   string
+	Score   float64
+	Signals []Signal
+}
+
+// Pump returns true for a suspected pump, false for a dump
+func (c CompositeEvent) Pump() bool {
+	return c.Score > 0
+}
+
+// Explain returns the contributing signals, strongest first
+func (c CompositeEvent) Explain() string {
+	signals := append([]Signal(nil), c.Signals...)
+	sort.SliceStable(signals, func(i, j int) bool {
+		return math.Abs(signals[i].Score) > math.Abs(signals[j].Score)
+	})
+	parts := make([]string, 0, len(signals))
+	for _, s := range signals {
+		parts = append(parts, fmt.Sprintf("%s %+g", s.Label, s.Score))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Composite sliding window of the signals of every base asset
+type Composite struct {
+	sync.Mutex
+	signals    map[string][]Signal
+	reported   map[string]time.Time // last event of base asset and direction
+	window     time.Duration
+	score      float64
+	minsignals int
+}
+
+// NewComposite returns a detector of base assets whose signals inside
+// window add up to score, from at least minsignals kinds of signal
+func NewComposite(window time.Duration, score float64, minsignals int) *Composite {
+	return &Composite{
+		signals:    make(map[string][]Signal),
+		reported:   make(map[string]time.Time),
+		window:     window,
+		score:      score,
+		minsignals: minsignals,
+	}
+}
+
+// Add returns a composite event when the signals of base cross the score
+// Adds a signal of base, a direction is reported once per window
+func (c *Composite) Add(base string, s Signal) *CompositeEvent {
+	c.Lock()
+	defer c.Unlock()
+
+	// Keep the signals inside the window
+	cutoff := s.Time.Add(-c.window)
+	signals := c.signals[base][:0]
+	for _, old := range c.signals[base] {
+		if old.Time.After(cutoff) {
+			signals = append(signals, old)
+		}
+	}
+	signals = append(signals, s)
+	c.signals[base] = signals
+
+	// Net score, the signals in its direction contribute
+	var score float64
+	for _, old := range signals {
+		score += old.Score
+	}
+	kinds := make(map[string]bool)
+	var contributing []Signal
+	for _, old := range signals {
+		if old.Score*score > 0 {
+			kinds[old.Kind] = true
+			contributing = append(contributing, old)
+		}
+	}
+	if math.Abs(score) < c.score || len(kinds) < c.minsignals {
+		return nil
+	}
+	direction := base + "+"
+	if score < 0 {
+		direction = base + "-"
+	}
+	if last, ok := c.reported[direction]; ok && last.After(cutoff) {
+		return nil
+	}
+	c.reported[direction] = s.Time
+	return &CompositeEvent{Base: base, Quote: s.Quote, Score: score, Signals: contributing}
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComposite(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	signal := func(minutes int, kind string, score float64) Signal {
+		return Signal{Time: start.Add(time.Duration(minutes) * time.Minute),
+			Kind: kind, Label: kind, Score: score, Quote: "USDT"}
+	}
+	tests := []struct {
+		name    string
+		signals []Signal
+		events  []float64 // scores of the reported events, in order
+	}{
+		{
+			name:    "below score",
+			signals: []Signal{signal(0, "PRICE_CHANGE", 1), signal(1, "TRADE", 1)},
+		},
+		{
+			name:    "pump",
+			signals: []Signal{signal(0, "PRICE_CHANGE", 2), signal(1, "TRADE", 1.5)},
+			events:  []float64{3.5},
+		},
+		{
+			name:    "dump",
+			signals: []Signal{signal(0, "PRICE_CHANGE", -2), signal(1, "TRADE", -2)},
+			events:  []float64{-4},
+		},
+		{
+			name:    "one kind",
+			signals: []Signal{signal(0, "TRADE", 2), signal(1, "TRADE", 2)},
+		},
+		{
+			name:    "opposite signals net out",
+			signals: []Signal{signal(0, "PRICE_CHANGE", 2), signal(1, "TRADE", -1), signal(2, "SWEEP", 1)},
+		},
+		{
+			name:    "expired signals",
+			signals: []Signal{signal(0, "PRICE_CHANGE", 2), signal(6, "TRADE", 1.5)},
+		},
+		{
+			name: "reported once per window",
+			signals: []Signal{signal(0, "PRICE_CHANGE", 2), signal(1, "TRADE", 1.5),
+				signal(2, "SWEEP", 1), signal(7, "TRADE", 2), signal(8, "SWEEP", 2)},
+			events: []float64{3.5, 4},
+		},
+		{
+			name: "directions reported apart",
+			signals: []Signal{signal(0, "PRICE_CHANGE", 2), signal(1, "TRADE", 1.5),
+				signal(6, "PRICE_CHANGE", -3), signal(6, "TRADE", -3)},
+			events: []float64{3.5, -6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComposite(5*time.Minute, 3, 2)
+			var events []float64
+			for _, s := range tt.signals {
+				if ev := c.Add("DOGE", s); ev != nil {
+					if ev.Base != "DOGE" || ev.Quote != "USDT" || ev.Pump() != (ev.Score > 0) {
+						t.Errorf("event %+v", ev)
+					}
+					events = append(events, ev.Score)
+				}
+			}
+			if len(events) != len(tt.events) {
+				t.Fatalf("events %v, want %v", events, tt.events)
+			}
+			for i := range events {
+				if events[i] != tt.events[i] {
+					t.Errorf("events %v, want %v", events, tt.events)
+				}
+			}
+		})
+	}
+}
+
+func TestCompositeExplain(t *testing.T) {
+	c := NewComposite(5*time.Minute, 3, 2)
+	now := time.Now()
+	c.Add("BTC", Signal{Time: now, Kind: "TRADE", Label: "Large Taker", Score: 1})
+	c.Add("BTC", Signal{Time: now, Kind: "SWEEP", Label: "Sell Sweep", Score: -0.5})
+	ev := c.Add("BTC", Signal{Time: now, Kind: "PRICE_CHANGE", Label: "Price Up", Score: 3})
+	if ev == nil {
+		t.Fatal("no composite event")
+	}
+	if want := "Price Up +3, Large Taker +1"; ev.Explain() != want {
+		t.Errorf("Explain() = %q, want %q", ev.Explain(), want)
+	}
+}
@@ -0,0 +1,63 @@
+package data
+
+// Market traded pair with its last price and 24h trade count
+type Market struct {
+	Base  string
+	Quote string
+	Price float64
+	Count uint64
+}
+
+// ConversionRates returns the rate converting every reachable asset into
+// target and the assets on its path. Markets are edges in both directions,
+// the best path has the fewest hops and, among those, the most traded
+// least liquid market.
+func ConversionRates(markets []Market, target string) (map[string]float64, map[string][]string) {
+	type edge struct {
+		to    string
+		rate  float64
+		count uint64
+	}
+	edges := make(map[string][]edge)
+	for _, m := range markets {
+		if m.Price <= 0 || m.Base == "" || m.Quote == "" {
+			continue
+		}
+		edges[m.Base] = append(edges[m.Base], edge{m.Quote, m.Price, m.Count})
+		edges[m.Quote] = append(edges[m.Quote], edge{m.Base, 1 / m.Price, m.Count})
+	}
+
+	rates := map[string]float64{target: 1}
+	paths := map[string][]string{target: {target}}
+	bottleneck := map[string]uint64{target: ^uint64(0)}
+	level := []string{target}
+	for len(level) > 0 {
+		// Best predecessor of every asset one hop further from target
+		next := make(map[string]bool)
+		var order []string
+		for _, asset := range level {
+			for _, e := range edges[asset] {
+				if _, done := rates[e.to]; done && !next[e.to] {
+					continue
+				}
+				// rate of e.to into asset is the inverse of the edge rate
+				liquidity := e.count
+				if bottleneck[asset] < liquidity {
+					liquidity = bottleneck[asset]
+				}
+				if next[e.to] && liquidity <= bottleneck[e.to] {
+					continue
+				}
+				if !next[e.to] {
+					next[e.to] = true
+					order = append(order, e.to)
+				}
+				rates[e.to] = rates[asset] / e.rate
+				bottleneck[e.to] = liquidity
+				paths[e.to] = append([]string{e.to}, paths[asset]...)
+			}
+		}
+		level = order
+	}
+	return rates, paths
+}
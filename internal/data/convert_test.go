@@ -0,0 +1,71 @@
+package data
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConversionRates(t *testing.T) {
+	markets := []Market{
+		{Base: "BTC", Quote: "USDT", Price: 50000, Count: 1000},
+		{Base: "ETH", Quote: "BTC", Price: 0.05, Count: 500},
+		{Base: "BNB", Quote: "USDT", Price: 300, Count: 800},
+		{Base: "ETH", Quote: "BNB", Price: 9, Count: 10},
+		{Base: "USDT", Quote: "TRY", Price: 30, Count: 400},
+		{Base: "DOGE", Quote: "TRY", Price: 3, Count: 100},
+		{Base: "XRP", Quote: "FOO", Price: 0, Count: 100},
+		{Base: "ABC", Quote: "XYZ", Price: 2, Count: 100},
+	}
+	rates, paths := ConversionRates(markets, "USDT")
+
+	tests := []struct {
+		name  string
+		asset string
+		rate  float64
+		path  []string
+	}{
+		{name: "target", asset: "USDT", rate: 1, path: []string{"USDT"}},
+		{name: "direct", asset: "BTC", rate: 50000, path: []string{"BTC", "USDT"}},
+		{name: "reversed pair", asset: "TRY", rate: 1.0 / 30, path: []string{"TRY", "USDT"}},
+		{name: "two hops over a reversed pair", asset: "DOGE", rate: 0.1, path: []string{"DOGE", "TRY", "USDT"}},
+		{name: "most traded path", asset: "ETH", rate: 2500, path: []string{"ETH", "BTC", "USDT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := rates[tt.asset]
+			if !ok {
+				t.Fatalf("no rate for %s", tt.asset)
+			}
+			if math.Abs(rate-tt.rate) > 1e-9*tt.rate {
+				t.Errorf("rate of %s = %v, want %v", tt.asset, rate, tt.rate)
+			}
+			if !reflect.DeepEqual(paths[tt.asset], tt.path) {
+				t.Errorf("path of %s = %v, want %v", tt.asset, paths[tt.asset], tt.path)
+			}
+		})
+	}
+
+	for _, asset := range []string{"XRP", "FOO", "ABC", "XYZ"} {
+		if _, ok := rates[asset]; ok {
+			t.Errorf("unreachable %s has a rate", asset)
+		}
+	}
+}
+
+func TestConversionRatesLiquidity(t *testing.T) {
+	// Two paths of two hops, the one whose least traded market is busier wins
+	markets := []Market{
+		{Base: "ETH", Quote: "BTC", Price: 0.05, Count: 50},
+		{Base: "BTC", Quote: "USDT", Price: 50000, Count: 100},
+		{Base: "ETH", Quote: "BNB", Price: 10, Count: 1000},
+		{Base: "BNB", Quote: "USDT", Price: 240, Count: 60},
+	}
+	rates, paths := ConversionRates(markets, "USDT")
+	if want := []string{"ETH", "BNB", "USDT"}; !reflect.DeepEqual(paths["ETH"], want) {
+		t.Errorf("path of ETH = %v, want %v", paths["ETH"], want)
+	}
+	if math.Abs(rates["ETH"]-2400) > 1e-9 {
+		t.Errorf("rate of ETH = %v, want 2400", rates["ETH"])
+	}
+}
@@ -0,0 +1,99 @@
+package data
+
+import (
+	"math"
+	"sort"
+)
+
+// Distribution rolling sample of the last trade notionals
+type Distribution struct {
+	Values []float64
+	next   int
+	count  int
+	sorted []float64
+	stale  int
+	// Running sums of log10 of the positive samples, for ZScore
+	logs     int
+	logsum   float64
+	logsumsq float64
+}
+
+// NewDistribution returns a distribution keeping size samples
+func NewDistribution(size int) *Distribution {
+	if size < 1 {
+		size = 1
+	}
+	return &Distribution{Values: make([]float64, size)}
+}
+
+// Add - Adds a sample, replacing the oldest when full
+func (d *Distribution) Add(v float64) {
+	if d.count == len(d.Values) {
+		d.addlog(d.Values[d.next], -1)
+	}
+	d.Values[d.next] = v
+	d.addlog(v, 1)
+	d.next = (d.next + 1) % len(d.Values)
+	if d.count < len(d.Values) {
+		d.count++
+	}
+	d.stale++
+	// Resums once per turn of the ring so rounding errors do not build up
+	if d.next == 0 {
+		d.logs, d.logsum, d.logsumsq = 0, 0, 0
+		for _, v := range d.Values[:d.count] {
+			d.addlog(v, 1)
+		}
+	}
+}
+
+// addlog - Adds (sign 1) or removes (sign -1) a sample from the log sums,
+// skipping the ones without a logarithm
+func (d *Distribution) addlog(v float64, sign int) {
+	if v <= 0 {
+		return
+	}
+	l := math.Log10(v)
+	d.logs += sign
+	d.logsum += float64(sign) * l
+	d.logsumsq += float64(sign) * l * l
+}
+
+// Len returns the number of samples
+func (d *Distribution) Len() int {
+	return d.count
+}
+
+// Percentile returns the p (0-100) percentile of the samples,
+// the sorted copy is refreshed after 2% of the samples changed
+func (d *Distribution) Percentile(p float64) float64 {
+	if d.count == 0 {
+		return 0
+	}
+	if d.sorted == nil || d.stale*50 >= d.count {
+		d.sorted = append(d.sorted[:0], d.Values[:d.count]...)
+		sort.Float64s(d.sorted)
+		d.stale = 0
+	}
+	i := int(math.Ceil(p/100*float64(len(d.sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(d.sorted) {
+		i = len(d.sorted) - 1
+	}
+	return d.sorted[i]
+}
+
+// ZScore returns the value z standard deviations above the mean,
+// computed over log10 of the samples since notionals are heavy tailed,
+// from the sums kept by Add
+func (d *Distribution) ZScore(z float64) float64 {
+	if d.logs == 0 {
+		return 0
+	}
+	n := float64(d.logs)
+	mean := d.logsum / n
+	deviation := math.Sqrt(math.Max(0, d.logsumsq/n-mean*mean))
+	return math.Pow(10, mean+z*deviation)
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"math"
+	"testing"
+)
+
+// zscore - ZScore recomputed over every sample
+func zscore(values []float64, z float64) float64 {
+	var sum, sumsq, n float64
+	for _, v := range values {
+		if v <= 0 {
+			continue
+		}
+		l := math.Log10(v)
+		sum += l
+		sumsq += l * l
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	mean := sum / n
+	return math.Pow(10, mean+z*math.Sqrt(math.Max(0, sumsq/n-mean*mean)))
+}
+
+func TestDistributionZScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		values []float64
+		z      float64
+		want   float64
+	}{
+		{name: "empty", size: 4, z: 2, want: 0},
+		{name: "no positive samples", size: 4, values: []float64{0, -5}, z: 2, want: 0},
+		{name: "constant", size: 4, values: []float64{100, 100, 100}, z: 3, want: 100},
+		{name: "geometric mean", size: 4, values: []float64{10, 1000}, z: 0, want: 100},
+		{name: "one deviation", size: 4, values: []float64{10, 1000}, z: 1, want: 1000},
+		{name: "skips zero", size: 4, values: []float64{10, 0, 1000}, z: -1, want: 10},
+		{name: "evicts oldest", size: 2, values: []float64{1e9, 10, 1000}, z: 0, want: 100},
+		{name: "evicts zero", size: 2, values: []float64{0, 10, 10}, z: 1, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDistribution(tt.size)
+			for _, v := range tt.values {
+				d.Add(v)
+			}
+			if got := d.ZScore(tt.z); math.Abs(got-tt.want) > 1e-9*math.Max(1, tt.want) {
+				t.Errorf("ZScore(%v) = %v, want %v", tt.z, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistributionRunningSums(t *testing.T) {
+	d := NewDistribution(50)
+	for i := 0; i < 1237; i++ {
+		d.Add(math.Exp(float64(i%97)/7) + float64(i%13))
+		want := zscore(d.Values[:d.Len()], 2.5)
+		if got := d.ZScore(2.5); math.Abs(got-want) > 1e-9*want {
+			t.Fatalf("after %d samples ZScore = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestDistributionPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{name: "empty", size: 4, p: 50, want: 0},
+		{name: "median", size: 5, values: []float64{5, 1, 4, 2, 3}, p: 50, want: 3},
+		{name: "lowest", size: 5, values: []float64{5, 1, 4, 2, 3}, p: 0, want: 1},
+		{name: "highest", size: 5, values: []float64{5, 1, 4, 2, 3}, p: 100, want: 5},
+		{name: "rolling", size: 3, values: []float64{100, 1, 2, 3}, p: 100, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDistribution(tt.size)
+			for _, v := range tt.values {
+				d.Add(v)
+			}
+			if got := d.Percentile(tt.p); got != tt.want {
+				t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
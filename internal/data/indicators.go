@@ -0,0 +1,209 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// IndicatorParams periods and levels of the technical indicators
+type IndicatorParams struct {
+	RSIPeriod      int
+	Overbought     float64
+	Oversold       float64
+	MACDFast       int
+	MACDSlow       int
+	MACDSignal     int
+	BollingerLen   int
+	BollingerWidth float64
+	EMAFast        int
+	EMASlow        int
+}
+
+// Indicators technical indicator values at the close of a kline
+type Indicators struct {
+	Time      time.Time
+	Close     float64
+	RSI       float64
+	MACD      float64
+	Signal    float64
+	Histogram float64 // MACD minus signal
+	Upper     float64 // Bollinger bands
+	Middle    float64
+	Lower     float64
+	FastEMA   float64
+	SlowEMA   float64
+}
+
+// EMA returns the exponential moving average series of values, seeded with
+// the simple average of the first period values
+func EMA(values []float64, period int) []float64 {
+	ema := make([]float64, len(values))
+	if period < 1 || len(values) < period {
+		return ema
+	}
+	k := 2 / float64(period+1)
+	sum := 0.0
+	for i := 0; i < period; i++ {
+		sum += values[i]
+	}
+	ema[period-1] = sum / float64(period)
+	for i := period; i < len(values); i++ {
+		ema[i] = values[i]*k + ema[i-1]*(1-k)
+	}
+	return ema
+}
+
+// RSI returns the Wilder relative strength index series of closes
+func RSI(closes []float64, period int) []float64 {
+	rsi := make([]float64, len(closes))
+	if period < 1 || len(closes) <= period {
+		return rsi
+	}
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		change := closes[i] - closes[i-1]
+		gain += math.Max(change, 0)
+		loss += math.Max(-change, 0)
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	index := func() float64 {
+		if loss == 0 {
+			return 100
+		}
+		return 100 - 100/(1+gain/loss)
+	}
+	rsi[period] = index()
+	for i := period + 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		gain = (gain*float64(period-1) + math.Max(change, 0)) / float64(period)
+		loss = (loss*float64(period-1) + math.Max(-change, 0)) / float64(period)
+		rsi[i] = index()
+	}
+	return rsi
+}
+
+// ComputeIndicators returns the indicators at the last two closes, the
+// closes must cover the longest period and the MACD signal
+func ComputeIndicators(times []time.Time, closes []float64, p IndicatorParams) (previous, last Indicators, ok bool) {
+	n := len(closes)
+	longest := p.MACDSlow + p.MACDSignal
+	for _, period := range []int{p.RSIPeriod + 1, p.BollingerLen, p.EMASlow, p.EMAFast} {
+		if period > longest {
+			longest = period
+		}
+	}
+	if n < longest+1 || len(times) != n {
+		return previous, last, false
+	}
+
+	rsi := RSI(closes, p.RSIPeriod)
+	fast, slow := EMA(closes, p.MACDFast), EMA(closes, p.MACDSlow)
+	macd := make([]float64, 0, n)
+	for i := p.MACDSlow - 1; i < n; i++ {
+		macd = append(macd, fast[i]-slow[i])
+	}
+	signal := EMA(macd, p.MACDSignal)
+	emafast, emaslow := EMA(closes, p.EMAFast), EMA(closes, p.EMASlow)
+
+	at := func(i int) Indicators {
+		ind := Indicators{
+			Time:    times[i],
+			Close:   closes[i],
+			RSI:     rsi[i],
+			FastEMA: emafast[i],
+			SlowEMA: emaslow[i],
+		}
+		m := i - (p.MACDSlow - 1)
+		ind.MACD, ind.Signal = macd[m], signal[m]
+		ind.Histogram = ind.MACD - ind.Signal
+		window := closes[i-p.BollingerLen+1 : i+1]
+		var sum, sumsq float64
+		for _, c := range window {
+			sum += c
+			sumsq += c * c
+		}
+		mean := sum / float64(len(window))
+		deviation := math.Sqrt(math.Max(0, sumsq/float64(len(window))-mean*mean))
+		ind.Middle = mean
+		ind.Upper = mean + p.BollingerWidth*deviation
+		ind.Lower = mean - p.BollingerWidth*deviation
+		return ind
+	}
+	return at(n - 2), at(n - 1), true
+}
+
+// IndicatorSignal event type and label of an indicator crossing
+type IndicatorSignal struct {
+	EventType string
+	Label     string
+	Value     float64
+}
+
+// IndicatorSignals returns the crossings from previous to last: RSI
+// entering the overbought or oversold levels, MACD crossing its signal,
+// the close breaking out of the Bollinger bands and the EMA crossovers
+func IndicatorSignals(previous, last Indicators, p IndicatorParams) []IndicatorSignal {
+	signals := make([]IndicatorSignal, 0)
+	if previous.RSI <= p.Overbought && last.RSI > p.Overbought {
+		signals = append(signals, IndicatorSignal{"RSI_OVERBOUGHT", "RSI Overbought", last.RSI})
+	}
+	if previous.RSI >= p.Oversold && last.RSI < p.Oversold {
+		signals = append(signals, IndicatorSignal{"RSI_OVERSOLD", "RSI Oversold", last.RSI})
+	}
+	if previous.Histogram <= 0 && last.Histogram > 0 {
+		signals = append(signals, IndicatorSignal{"MACD_CROSS_UP", "MACD Cross Up", last.Histogram})
+	}
+	if previous.Histogram >= 0 && last.Histogram < 0 {
+		signals = append(signals, IndicatorSignal{"MACD_CROSS_DOWN", "MACD Cross Down", last.Histogram})
+	}
+	if previous.Close <= previous.Upper && last.Close > last.Upper {
+		signals = append(signals, IndicatorSignal{"BB_BREAKOUT_UP", "Band Breakout Up", last.Close})
+	}
+	if previous.Close >= previous.Lower && last.Close < last.Lower {
+		signals = append(signals, IndicatorSignal{"BB_BREAKOUT_DOWN", "Band Breakout Down", last.Close})
+	}
+	if previous.FastEMA <= previous.SlowEMA && last.FastEMA > last.SlowEMA {
+		signals = append(signals, IndicatorSignal{"EMA_CROSS_UP", "EMA Cross Up", last.FastEMA})
+	}
+	if previous.FastEMA >= previous.SlowEMA && last.FastEMA < last.SlowEMA {
+		signals = append(signals, IndicatorSignal{"EMA_CROSS_DOWN", "EMA Cross Down", last.FastEMA})
+	}
+	return signals
+}
+
+// Explain returns the indicator values in a single line
+func (ind Indicators) Explain(p IndicatorParams) string {
+	return fmt.Sprintf("RSI %.1f, MACD %.4g/%.4g, BB %.6g - %.6g, EMA%d %.6g EMA%d %.6g",
+		ind.RSI, ind.MACD, ind.Signal, ind.Lower, ind.Upper, p.EMAFast, ind.FastEMA, p.EMASlow, ind.SlowEMA)
+}
+
+// IndicatorSet last indicators of the watched symbols
+type IndicatorSet struct {
+	sync.Mutex
+	Pairs map[string]Indicators
+}
+
+// NewIndicatorSet returns an empty set
+func NewIndicatorSet() *IndicatorSet {
+	return &IndicatorSet{Pairs: make(map[string]Indicators)}
+}
+
+// Set - Keeps the last indicators of symbol, returns the ones replaced
+func (s *IndicatorSet) Set(symbol string, ind Indicators) (Indicators, bool) {
+	s.Lock()
+	defer s.Unlock()
+	previous, ok := s.Pairs[symbol]
+	s.Pairs[symbol] = ind
+	return previous, ok
+}
+
+// Pair returns the last indicators of symbol
+func (s *IndicatorSet) Pair(symbol string) (Indicators, bool) {
+	s.Lock()
+	defer s.Unlock()
+	ind, ok := s.Pairs[symbol]
+	return ind, ok
+}
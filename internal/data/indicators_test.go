@@ -0,0 +1,173 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{name: "too short", values: []float64{1, 2}, period: 3, want: []float64{0, 0}},
+		{name: "period one", values: []float64{3, 1, 2}, period: 1, want: []float64{3, 1, 2}},
+		// Seeded with the average of 1, 2, 3, then halfway to every value
+		{name: "linear", values: []float64{1, 2, 3, 4, 5, 6}, period: 3, want: []float64{0, 0, 2, 3, 4, 5}},
+		{name: "step", values: []float64{10, 10, 10, 20, 20}, period: 3, want: []float64{0, 0, 10, 15, 17.5}},
+		// Reference closes, k = 2/11 seeded with the average of the first ten
+		{
+			name:   "reference",
+			values: []float64{22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29, 22.15, 22.39},
+			period: 10,
+			want:   []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 22.221, 22.2081, 22.2412},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EMA(tt.values, tt.period)
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > 1e-4 {
+					t.Fatalf("EMA = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRSI(t *testing.T) {
+	// Wilder RSI reference closes, the published values are rounded to two
+	// decimals from rounded averages
+	closes := []float64{44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64}
+	reference := []float64{70.53, 66.32, 66.55, 69.41, 66.36, 57.97}
+	rsi := RSI(closes, 14)
+	for i := 0; i < 14; i++ {
+		if rsi[i] != 0 {
+			t.Errorf("RSI[%d] = %v before the period", i, rsi[i])
+		}
+	}
+	for i, want := range reference {
+		if got := rsi[14+i]; math.Abs(got-want) > 0.1 {
+			t.Errorf("RSI[%d] = %.2f, want %.2f", 14+i, got, want)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		closes []float64
+		want   float64
+	}{
+		{name: "only gains", closes: []float64{1, 2, 3, 4}, want: 100},
+		{name: "only losses", closes: []float64{4, 3, 2, 1}, want: 0},
+		{name: "flat", closes: []float64{1, 1, 1, 1}, want: 100},
+		// Averages 0.5/0.5, then 0.75/0.25 and 0.375/0.625
+		{name: "smoothed", closes: []float64{1, 2, 1, 2, 1}, want: 37.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rsi := RSI(tt.closes, 2)
+			if got := rsi[len(rsi)-1]; math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("RSI = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeIndicators(t *testing.T) {
+	p := IndicatorParams{RSIPeriod: 3, Overbought: 70, Oversold: 30, MACDFast: 2, MACDSlow: 4,
+		MACDSignal: 2, BollingerLen: 3, BollingerWidth: 2, EMAFast: 2, EMASlow: 3}
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	closes := []float64{10, 11, 12, 11, 13, 14, 13}
+	times := make([]time.Time, len(closes))
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * time.Minute)
+	}
+
+	if _, _, ok := ComputeIndicators(times[:5], closes[:5], p); ok {
+		t.Error("computed indicators without the MACD signal closes")
+	}
+	if _, _, ok := ComputeIndicators(times[:6], closes, p); ok {
+		t.Error("computed indicators of mismatched times")
+	}
+
+	previous, last, ok := ComputeIndicators(times, closes, p)
+	if !ok {
+		t.Fatal("indicators not computed")
+	}
+	if !previous.Time.Equal(times[5]) || !last.Time.Equal(times[6]) || last.Close != 13 {
+		t.Errorf("indicators at %v and %v close %v", previous.Time, last.Time, last.Close)
+	}
+	fast, slow := EMA(closes, 2), EMA(closes, 4)
+	macd := []float64{fast[3] - slow[3], fast[4] - slow[4], fast[5] - slow[5], fast[6] - slow[6]}
+	signal := EMA(macd, 2)
+	// Bands of the last three closes 13, 14 and 13
+	mean := (13.0 + 14 + 13) / 3
+	deviation := math.Sqrt(((13-mean)*(13-mean)*2 + (14-mean)*(14-mean)) / 3)
+	want := Indicators{
+		Time: times[6], Close: 13, RSI: RSI(closes, 3)[6],
+		MACD: macd[3], Signal: signal[3], Histogram: macd[3] - signal[3],
+		Upper: mean + 2*deviation, Middle: mean, Lower: mean - 2*deviation,
+		FastEMA: EMA(closes, 2)[6], SlowEMA: EMA(closes, 3)[6],
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"RSI", last.RSI, want.RSI},
+		{"MACD", last.MACD, want.MACD},
+		{"Signal", last.Signal, want.Signal},
+		{"Histogram", last.Histogram, want.Histogram},
+		{"Upper", last.Upper, want.Upper},
+		{"Middle", last.Middle, want.Middle},
+		{"Lower", last.Lower, want.Lower},
+		{"FastEMA", last.FastEMA, want.FastEMA},
+		{"SlowEMA", last.SlowEMA, want.SlowEMA},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestIndicatorSignals(t *testing.T) {
+	p := IndicatorParams{Overbought: 70, Oversold: 30}
+	tests := []struct {
+		name           string
+		previous, last Indicators
+		want           []string
+	}{
+		{name: "none", previous: Indicators{RSI: 50}, last: Indicators{RSI: 60}},
+		{name: "rsi overbought", previous: Indicators{RSI: 70}, last: Indicators{RSI: 71}, want: []string{"RSI_OVERBOUGHT"}},
+		{name: "rsi oversold", previous: Indicators{RSI: 30}, last: Indicators{RSI: 29}, want: []string{"RSI_OVERSOLD"}},
+		{name: "rsi stays overbought", previous: Indicators{RSI: 75}, last: Indicators{RSI: 80}},
+		{name: "macd cross up", previous: Indicators{RSI: 50, Histogram: -1}, last: Indicators{RSI: 50, Histogram: 1},
+			want: []string{"MACD_CROSS_UP"}},
+		{name: "macd cross down", previous: Indicators{RSI: 50, Histogram: 1}, last: Indicators{RSI: 50, Histogram: -1},
+			want: []string{"MACD_CROSS_DOWN"}},
+		{name: "band breakout up", previous: Indicators{RSI: 50, Close: 10, Upper: 11, Lower: 9},
+			last: Indicators{RSI: 50, Close: 12, Upper: 11, Lower: 9}, want: []string{"BB_BREAKOUT_UP"}},
+		{name: "band breakout down", previous: Indicators{RSI: 50, Close: 10, Upper: 11, Lower: 9},
+			last: Indicators{RSI: 50, Close: 8, Upper: 11, Lower: 9}, want: []string{"BB_BREAKOUT_DOWN"}},
+		{name: "ema cross up", previous: Indicators{RSI: 50, FastEMA: 9, SlowEMA: 10},
+			last: Indicators{RSI: 50, FastEMA: 11, SlowEMA: 10}, want: []string{"EMA_CROSS_UP"}},
+		{name: "ema cross down", previous: Indicators{RSI: 50, FastEMA: 11, SlowEMA: 10},
+			last: Indicators{RSI: 50, FastEMA: 9, SlowEMA: 10}, want: []string{"EMA_CROSS_DOWN"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := IndicatorSignals(tt.previous, tt.last, p)
+			if len(signals) != len(tt.want) {
+				t.Fatalf("signals %+v, want %v", signals, tt.want)
+			}
+			for i, s := range signals {
+				if s.EventType != tt.want[i] {
+					t.Errorf("signals %+v, want %v", signals, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -7,9 +7,17 @@ Esc:  Disable Selection
 \:    Get Trades of Selection
 /:    Input Asset to Subscribe
 Ctrl-C: Exit`,
+	"history": `Tab:  Switch Query/Table
+Enter: Enable Selection
+Enter: Again to show detail
+Esc:  Disable Selection
+o:    Open Web Trade Page
+n, p: Next, Previous Page
+q:    Back to Live Feed`,
 	"notenoughdata":   "Not enough Buy/Sell data",
 	"notenoughtrades": "Not enough trades, subscribe to pairs",
 	"termsizemodal":   "Too small terminal ...",
+	"volumeprofile":   "Select a pair to show the volume at price of its stored trades",
 	"waitingfordata":  "Waiting for live data ...",
 	"helpmodal": `Key shortcuts:
 Navigate the live feed table using VIM key shortcuts:
@@ -23,10 +31,21 @@ Enter: Enter Selection Mode
 Enter: In Selection Mode select symbol to show details
 \:	In Selection Mode, subscribe symbol to trades feed
 o: In Selection Mode, Launch Web Trade Page
+a: In Selection Mode, anchor the pair VWAP at the selected event
+e: Edit the large trade threshold overrides of pairs or quote assets
 Esc: Exit Selection Mode
 /: Display Input Form to subscribe a symbol to trades feed
 u: Unsubscribe pair from trades feed
 U: Unsubscribe all pairs from trades feed
+b: Browse stored events and trades history, n/p: next/previous page, q: back
+v: Cycle the volume profile range of the selected pair
+p: Cycle the popularity view: assets, quotes, notices, bullish, bearish
+s: Show the hit rate statistics of the stored notices, r: reload, q: back
+c: Show the co-movement heatmap of base assets, r: reload, q: back
+S: Show the summary of the session or last hours, p: period, w: write markdown, q: back
+r: Rank the popular assets by strength relative to BTC and ETH, r: reload, q: back
+t: Cycle the trade trend window
+T: Cycle the trade trend view: all pairs, selected pair, one bar per pair
 h, H: Display this Help Modal
 Ctrl-C: quit program
 `,
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"sync"
+	"time"
+)
+
+// RateNotice returns true for the exchange notice types counted in the
+// notice rates, the events derived by gobit are left out
+func RateNotice(noticetype string) bool {
+	switch noticetype {
+	case "PRICE_CHANGE", "PRICE_BREAKTHROUGH", "VOLUME_PRICE", "BLOCK_TRADE":
+		return true
+	}
+	return false
+}
+
+// NoticeRate notices of a base asset during the window, rates per hour
+type NoticeRate struct {
+	Asset    string
+	Symbol   Symbol // pair of the last notice
+	Count    int
+	Rate     float64
+	Baseline float64
+}
+
+// Multiple returns the rate as a multiple of the baseline
+func (r NoticeRate) Multiple() float64 {
+	if r.Baseline <= 0 {
+		return 0
+	}
+	return r.Rate / r.Baseline
+}
+
+// NoticeRates notices per hour of every base asset against its baseline
+// An asset spikes when it has at least mincount notices during the window
+// and their hourly rate is factor times its baseline. Baselines add one
+// notice to the stored count, so assets without history have a low one.
+type NoticeRates struct {
+	sync.Mutex
+	window   time.Duration
+	factor   float64
+	mincount int
+	notices  map[string][]time.Time
+	symbols  map[string]Symbol
+	baseline map[string]float64
+	hours    float64 // covered by the baseline
+	flagged  map[string]bool
+}
+
+// NewNoticeRates returns empty notice rates of window
+func NewNoticeRates(window time.Duration, factor float64, mincount int) *NoticeRates {
+	return &NoticeRates{
+		window:   window,
+		factor:   factor,
+		mincount: mincount,
+		notices:  make(map[string][]time.Time),
+		symbols:  make(map[string]Symbol),
+		baseline: make(map[string]float64),
+		hours:    window.Hours(),
+		flagged:  make(map[string]bool),
+	}
+}
+
+// SetBaseline - Replaces the baselines with the notice counts of every base
+// asset over span, spans shorter than the window count as the window
+func (r *NoticeRates) SetBaseline(counts map[string]int, span time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+	if span < r.window {
+		span = r.window
+	}
+	r.hours = span.Hours()
+	r.baseline = make(map[string]float64, len(counts))
+	for asset, n := range counts {
+		r.baseline[asset] = float64(n) / r.hours
+	}
+}
+
+// Add - Adds a notice of the pair symbol at time t
+func (r *NoticeRates) Add(t time.Time, symbol Symbol) {
+	if symbol.BaseAsset == "" {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.notices[symbol.BaseAsset] = append(r.notices[symbol.BaseAsset], t)
+	r.symbols[symbol.BaseAsset] = symbol
+}
+
+// rate - Notice rate of asset at now, dropping the notices out of the window
+func (r *NoticeRates) rate(asset string, now time.Time) NoticeRate {
+	times := r.notices[asset]
+	expired := 0
+	for expired < len(times) && now.Sub(times[expired]) > r.window {
+		expired++
+	}
+	times = times[expired:]
+	if len(times) == 0 {
+		delete(r.notices, asset)
+		delete(r.symbols, asset)
+	} else {
+		r.notices[asset] = times
+	}
+	return NoticeRate{
+		Asset:    asset,
+		Symbol:   r.symbols[asset],
+		Count:    len(times),
+		Rate:     float64(len(times)) / r.window.Hours(),
+		Baseline: r.baseline[asset] + 1/r.hours,
+	}
+}
+
+// spiking - Whether a rate is far above its baseline
+func (r *NoticeRates) spiking(rate NoticeRate) bool {
+	return rate.Count >= r.mincount && rate.Rate >= r.factor*rate.Baseline
+}
+
+// Check returns the rate of asset at now and true when it starts spiking,
+// an asset is flagged once until its rate falls back
+func (r *NoticeRates) Check(asset string, now time.Time) (NoticeRate, bool) {
+	r.Lock()
+	defer r.Unlock()
+	rate := r.rate(asset, now)
+	if !r.spiking(rate) {
+		delete(r.flagged, asset)
+		return rate, false
+	}
+	if r.flagged[asset] {
+		return rate, false
+	}
+	r.flagged[asset] = true
+	return rate, true
+}
+
+// Spiking returns true while the notice rate of asset is far above its
+// baseline at now
+func (r *NoticeRates) Spiking(asset string, now time.Time) bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.spiking(r.rate(asset, now))
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNoticeRates(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		asset    string
+		notices  []int // minutes from start
+		now      int
+		count    int
+		baseline float64
+		spike    bool
+	}{
+		{name: "no notices", asset: "DOGE", now: 0, baseline: 1.0 / 24},
+		{name: "spike without history", asset: "DOGE", notices: []int{0, 1, 2}, now: 2, count: 3, baseline: 1.0 / 24, spike: true},
+		{name: "below min count", asset: "DOGE", notices: []int{0, 1}, now: 2, count: 2, baseline: 1.0 / 24},
+		{name: "within baseline", asset: "BTC", notices: []int{0, 1, 2, 3, 4}, now: 4, count: 5, baseline: 2 + 1.0/24},
+		{name: "above baseline", asset: "ETH", notices: []int{0, 1, 2, 3}, now: 4, count: 4, baseline: 1.0/24 + 1.0/24, spike: true},
+		{name: "expired notices", asset: "DOGE", notices: []int{0, 1, 2, 70}, now: 70, count: 1, baseline: 1.0 / 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewNoticeRates(time.Hour, 3, 3)
+			r.SetBaseline(map[string]int{"BTC": 48, "ETH": 1}, 24*time.Hour)
+			for _, m := range tt.notices {
+				r.Add(start.Add(time.Duration(m)*time.Minute), Symbol{Symbol: tt.asset + "USDT",
+					BaseAsset: tt.asset, QuoteAsset: "USDT"})
+			}
+			rate, spike := r.Check(tt.asset, start.Add(time.Duration(tt.now)*time.Minute))
+			if rate.Count != tt.count || rate.Rate != float64(tt.count) || spike != tt.spike {
+				t.Errorf("rate %+v spike %v, want %d notices spike %v", rate, spike, tt.count, tt.spike)
+			}
+			if math.Abs(rate.Baseline-tt.baseline) > 1e-9 {
+				t.Errorf("baseline %v, want %v", rate.Baseline, tt.baseline)
+			}
+			if tt.count > 0 && rate.Symbol.BaseAsset != tt.asset {
+				t.Errorf("symbol %+v of another asset", rate.Symbol)
+			}
+		})
+	}
+}
+
+func TestNoticeRatesFlagged(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := NewNoticeRates(time.Hour, 3, 3)
+	symbol := Symbol{Symbol: "DOGEUSDT", BaseAsset: "DOGE", QuoteAsset: "USDT"}
+	checks := []struct {
+		minute int
+		add    bool
+		spike  bool
+	}{
+		{0, true, false},
+		{1, true, false},
+		{2, true, true},
+		{3, true, false}, // still spiking, flagged once
+		{90, false, false},
+		{91, true, false},
+		{92, true, false},
+		{93, true, true}, // spiking again after falling back
+	}
+	for _, c := range checks {
+		now := start.Add(time.Duration(c.minute) * time.Minute)
+		if c.add {
+			r.Add(now, symbol)
+		}
+		if _, spike := r.Check("DOGE", now); spike != c.spike {
+			t.Errorf("minute %d spike %v, want %v", c.minute, spike, c.spike)
+		}
+	}
+	if !r.Spiking("DOGE", start.Add(93*time.Minute)) {
+		t.Error("not spiking after the flag")
+	}
+}
+
+func TestNoticeRateMultiple(t *testing.T) {
+	tests := []struct {
+		rate NoticeRate
+		want float64
+	}{
+		{NoticeRate{Rate: 6, Baseline: 2}, 3},
+		{NoticeRate{Rate: 6}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.rate.Multiple(); got != tt.want {
+			t.Errorf("Multiple() of %+v = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
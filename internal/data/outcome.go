@@ -0,0 +1,113 @@
+package data
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// OutcomeHorizons times after a notice its pair price is recorded
+var OutcomeHorizons = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour}
+
+// Outcome prices of a pair at and after a notice, zero until known
+type Outcome struct {
+	Timestamp  time.Time // notice time
+	Symbol     string
+	BaseAsset  string
+	QuoteAsset string
+	NoticeType string
+	EventType  string
+	Period     string
+	Direction  int // signalled direction, 1 up and -1 down
+	Price      float64
+	Prices     []float64 // at OutcomeHorizons
+}
+
+// Direction returns the signalled direction of a notice event type,
+// 1 for rises, -1 for falls and 0 when it has none
+func Direction(eventtype string) int {
+	for _, down := range []string{"DOWN", "DROP", "SELL", "DUMP"} {
+		if strings.Contains(eventtype, down) {
+			return -1
+		}
+	}
+	for _, up := range []string{"UP", "RISE", "BUY", "PUMP"} {
+		if strings.Contains(eventtype, up) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Move returns the price move at horizon i in the signalled direction,
+// in percent, and false while unknown
+func (o Outcome) Move(i int) (float64, bool) {
+	if o.Price == 0 || i >= len(o.Prices) || o.Prices[i] == 0 {
+		return 0, false
+	}
+	return float64(o.Direction) * (o.Prices[i]/o.Price - 1) * 100, true
+}
+
+// OutcomeStat how often and how far the price of a notice type and level
+// kept moving in the signalled direction at every horizon
+type OutcomeStat struct {
+	NoticeType string
+	EventType  string
+	Count      []int
+	Hits       []int
+	Move       []float64 // average move in percent
+}
+
+// HitRate returns the percent of outcomes at horizon i moving in the
+// signalled direction
+func (s OutcomeStat) HitRate(i int) float64 {
+	if s.Count[i] == 0 {
+		return 0
+	}
+	return float64(s.Hits[i]) / float64(s.Count[i]) * 100
+}
+
+// OutcomeStats returns the statistics per notice type and level
+func OutcomeStats(outcomes []Outcome) []OutcomeStat {
+	n := len(OutcomeHorizons)
+	index := make(map[string]int)
+	var stats []OutcomeStat
+	for _, o := range outcomes {
+		key := o.NoticeType + " " + o.EventType
+		i, ok := index[key]
+		if !ok {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, OutcomeStat{
+				NoticeType: o.NoticeType,
+				EventType:  o.EventType,
+				Count:      make([]int, n),
+				Hits:       make([]int, n),
+				Move:       make([]float64, n),
+			})
+		}
+		for h := 0; h < n; h++ {
+			if move, ok := o.Move(h); ok {
+				stats[i].Count[h]++
+				stats[i].Move[h] += move
+				if move > 0 {
+					stats[i].Hits[h]++
+				}
+			}
+		}
+	}
+	for i := range stats {
+		for h := 0; h < n; h++ {
+			if stats[i].Count[h] > 0 {
+				stats[i].Move[h] /= float64(stats[i].Count[h])
+			}
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].NoticeType != stats[j].NoticeType {
+			return stats[i].NoticeType < stats[j].NoticeType
+		}
+		return stats[i].EventType < stats[j].EventType
+	})
+	return stats
+}
@@ -0,0 +1,88 @@
+package data
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		eventtype string
+		want      int
+	}{
+		{"UP_1", 1},
+		{"DOWN_3", -1},
+		{"HIGH_VOLUME_RISE_2", 1},
+		{"HIGH_VOLUME_DROP_1", -1},
+		{"BLOCK_TRADES_BUY", 1},
+		{"BLOCK_TRADES_SELL", -1},
+		{"PUMP_SUSPECTED", 1},
+		{"DUMP_SUSPECTED", -1},
+		{"UP_BREAKTHROUGH", 1},
+		{"HIGH_VOLATILITY", 0},
+	}
+	for _, tt := range tests {
+		if got := Direction(tt.eventtype); got != tt.want {
+			t.Errorf("Direction(%s) = %d, want %d", tt.eventtype, got, tt.want)
+		}
+	}
+}
+
+func TestOutcomeMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome Outcome
+		horizon int
+		want    float64
+		ok      bool
+	}{
+		{name: "rise up", outcome: Outcome{Direction: 1, Price: 100, Prices: []float64{102}}, want: 2, ok: true},
+		{name: "rise down", outcome: Outcome{Direction: -1, Price: 100, Prices: []float64{102}}, want: -2, ok: true},
+		{name: "unknown price", outcome: Outcome{Direction: 1, Price: 100, Prices: []float64{0}}},
+		{name: "no notice price", outcome: Outcome{Direction: 1, Prices: []float64{102}}},
+		{name: "past the horizons", outcome: Outcome{Direction: 1, Price: 100, Prices: []float64{102}}, horizon: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			move, ok := tt.outcome.Move(tt.horizon)
+			if ok != tt.ok || math.Abs(move-tt.want) > 1e-9 {
+				t.Errorf("Move(%d) = %v %v, want %v %v", tt.horizon, move, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestOutcomeStats(t *testing.T) {
+	outcome := func(noticetype, eventtype string, direction int, prices ...float64) Outcome {
+		return Outcome{NoticeType: noticetype, EventType: eventtype, Direction: direction, Price: 100, Prices: prices}
+	}
+	stats := OutcomeStats([]Outcome{
+		outcome("PRICE_CHANGE", "UP_1", 1, 101, 103, 0, 0),
+		outcome("BLOCK_TRADE", "BLOCK_TRADES_SELL", -1, 99, 101, 98, 0),
+		outcome("PRICE_CHANGE", "UP_1", 1, 99, 101, 0, 0),
+	})
+	if len(stats) != 2 || stats[0].NoticeType != "BLOCK_TRADE" || stats[1].EventType != "UP_1" {
+		t.Fatalf("stats %+v, want BLOCK_TRADE then PRICE_CHANGE", stats)
+	}
+	up := stats[1]
+	tests := []struct {
+		horizon int
+		count   int
+		hitrate float64
+		move    float64
+	}{
+		{0, 2, 50, 0},
+		{1, 2, 100, 2},
+		{2, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if up.Count[tt.horizon] != tt.count || up.HitRate(tt.horizon) != tt.hitrate ||
+			math.Abs(up.Move[tt.horizon]-tt.move) > 1e-9 {
+			t.Errorf("horizon %d: %d notices hit rate %v move %v, want %d %v %v", tt.horizon,
+				up.Count[tt.horizon], up.HitRate(tt.horizon), up.Move[tt.horizon], tt.count, tt.hitrate, tt.move)
+		}
+	}
+	if sell := stats[0]; sell.Hits[0] != 1 || sell.Hits[1] != 0 || sell.Hits[2] != 1 || sell.Count[3] != 0 {
+		t.Errorf("sell stats %+v", sell)
+	}
+}
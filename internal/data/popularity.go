@@ -0,0 +1,206 @@
+package data
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scores below are dropped from the ranking
+const minpopularity = 0.01
+
+// popularityscore decaying activity of a name
+type popularityscore struct {
+	Score   float64
+	Volume  float64 // sum of the volumes counted in the average
+	Samples float64 // number of volumes counted in the average
+	Updated time.Time
+}
+
+// decayto - Decays the sums from their last update to t
+func (s *popularityscore) decayto(t time.Time, decay time.Duration) {
+	if !t.After(s.Updated) {
+		return
+	}
+	f := math.Exp(-float64(t.Sub(s.Updated)) / float64(decay))
+	s.Score *= f
+	s.Volume *= f
+	s.Samples *= f
+	s.Updated = t
+}
+
+// Popularity incremental ranking of names by their weighted activity
+// Every activity adds its weight to the score of a name, scores fall
+// exponentially with the decay time constant. Rank changes are relative
+// to the ranking of one interval before.
+type Popularity struct {
+	sync.Mutex
+	decay    time.Duration
+	interval time.Duration
+	scores   map[string]*popularityscore
+	last     map[string]int // ranks of the last ranking
+	previous map[string]int // ranks compared against
+	since    time.Time
+}
+
+// NewPopularity returns an empty ranking decaying over decay, comparing
+// ranks against the ranking kept every interval
+func NewPopularity(decay, interval time.Duration) *Popularity {
+	if decay <= 0 {
+		decay = time.Minute
+	}
+	return &Popularity{
+		decay:    decay,
+		interval: interval,
+		scores:   make(map[string]*popularityscore),
+	}
+}
+
+// Add - Adds an activity of name at time t, volumes greater than zero
+// are counted in the average volume
+func (p *Popularity) Add(name string, t time.Time, weight, volume float64) {
+	if name == "" || weight <= 0 {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	s, ok := p.scores[name]
+	if !ok {
+		s = &popularityscore{Updated: t}
+		p.scores[name] = s
+	}
+	// Activities older than the last update are discounted instead
+	f := 1.0
+	if t.Before(s.Updated) {
+		f = math.Exp(-float64(s.Updated.Sub(t)) / float64(p.decay))
+	}
+	s.decayto(t, p.decay)
+	s.Score += weight * f
+	if volume > 0 {
+		s.Volume += volume * f
+		s.Samples += f
+	}
+}
+
+// Rank returns the n most popular names at now with their score, the
+// average volume and the rank change, dropping names faded away
+func (p *Popularity) Rank(now time.Time, n int) []AssetStat {
+	p.Lock()
+	defer p.Unlock()
+	stats := make([]AssetStat, 0, len(p.scores))
+	for name, s := range p.scores {
+		s.decayto(now, p.decay)
+		if s.Score < minpopularity {
+			delete(p.scores, name)
+			continue
+		}
+		stat := AssetStat{Name: name, Momentum: s.Score}
+		if s.Samples > 0 {
+			stat.AvgVolume = s.Volume / s.Samples
+		}
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Momentum != stats[j].Momentum {
+			return stats[i].Momentum > stats[j].Momentum
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	// Keep the last ranking as reference once per interval
+	if p.last != nil && now.Sub(p.since) >= p.interval {
+		p.previous, p.since = p.last, now
+	} else if p.last == nil {
+		p.since = now
+	}
+	p.last = make(map[string]int, len(stats))
+	for i := range stats {
+		p.last[stats[i].Name] = i + 1
+		if p.previous == nil {
+			continue
+		}
+		if rank, ok := p.previous[stats[i].Name]; ok {
+			stats[i].Change = rank - (i + 1)
+		} else {
+			stats[i].New = true
+		}
+	}
+
+	if n > 0 && len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+// Activity notice, large trade or whale sweep counted in the popularity views
+type Activity struct {
+	Base      string
+	Quote     string
+	Kind      string  // notice type, TRADE or SWEEP
+	Direction int     // 1 bullish, -1 bearish, 0 unknown
+	Weight    float64 // popularity weight of the kind
+	Amount    float64 // in base asset, 0 when not a trade
+	Notional  float64 // in default quote, 0 when unknown
+}
+
+// Popularity views
+const (
+	PopularityAssets  = iota // base assets
+	PopularityQuotes         // quote assets
+	PopularityNotices        // notice types, large trades and sweeps
+	PopularityBullish        // base assets of bullish activities
+	PopularityBearish        // base assets of bearish activities
+	PopularityViews
+)
+
+// Short names of the activity kinds in the notices view
+var popularitykinds = map[string]string{
+	"PRICE_CHANGE":       "PRICE",
+	"PRICE_BREAKTHROUGH": "BREAK",
+	"VOLUME_PRICE":       "VOLUME",
+	"BLOCK_TRADE":        "BLOCK",
+	"COMPOSITE":          "PUMP/DUMP",
+	"TRADE":              "TRADE",
+	"SWEEP":              "SWEEP",
+}
+
+// PopularityRanks rankings of every popularity view
+type PopularityRanks [PopularityViews]*Popularity
+
+// NewPopularityRanks returns empty rankings of every view decaying over
+// decay, comparing ranks against the rankings kept every interval
+func NewPopularityRanks(decay, interval time.Duration) *PopularityRanks {
+	var r PopularityRanks
+	for i := range r {
+		r[i] = NewPopularity(decay, interval)
+	}
+	return &r
+}
+
+// Add - Adds an activity at time t to every view, base assets average the
+// amount and the other views the notional
+func (r *PopularityRanks) Add(a Activity, t time.Time) {
+	r[PopularityAssets].Add(a.Base, t, a.Weight, a.Amount)
+	r[PopularityQuotes].Add(a.Quote, t, a.Weight, a.Notional)
+	kind, ok := popularitykinds[a.Kind]
+	if !ok {
+		kind = a.Kind
+	}
+	r[PopularityNotices].Add(kind, t, a.Weight, a.Notional)
+	switch {
+	case a.Direction > 0:
+		r[PopularityBullish].Add(a.Base, t, a.Weight, a.Amount)
+	case a.Direction < 0:
+		r[PopularityBearish].Add(a.Base, t, a.Weight, a.Amount)
+	}
+}
+
+// Rank returns the n most popular names of every view at now, all views
+// are ranked so their rank changes stay current
+func (r *PopularityRanks) Rank(now time.Time, n int) (ranks [PopularityViews][]AssetStat) {
+	for i := range r {
+		ranks[i] = r[i].Rank(now, n)
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPopularityRank(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := NewPopularity(time.Minute, time.Hour)
+	p.Add("BTC", start, 1, 10)
+	p.Add("BTC", start, 1, 0) // not counted in the average volume
+	p.Add("ETH", start, 3, 4)
+	p.Add("DOGE", start.Add(-time.Minute), 1, 0) // late, discounted
+	p.Add("", start, 5, 0)
+	p.Add("SOL", start, 0, 0)
+
+	tests := []struct {
+		name     string
+		momentum float64
+		volume   float64
+	}{
+		{"ETH", 3, 4},
+		{"BTC", 2, 10},
+		{"DOGE", math.Exp(-1), 0},
+	}
+	ranks := p.Rank(start, 0)
+	if len(ranks) != len(tests) {
+		t.Fatalf("ranks %+v, want %d names", ranks, len(tests))
+	}
+	for i, tt := range tests {
+		if ranks[i].Name != tt.name || math.Abs(ranks[i].Momentum-tt.momentum) > 1e-9 || ranks[i].AvgVolume != tt.volume {
+			t.Errorf("rank %d = %+v, want %s %v %v", i+1, ranks[i], tt.name, tt.momentum, tt.volume)
+		}
+	}
+
+	// One decay time later every score fell by e, the top one is cut
+	ranks = p.Rank(start.Add(time.Minute), 2)
+	if len(ranks) != 2 || math.Abs(ranks[0].Momentum-3/math.E) > 1e-9 {
+		t.Errorf("decayed ranks %+v", ranks)
+	}
+	// Faded names are dropped
+	if ranks = p.Rank(start.Add(10*time.Minute), 0); len(ranks) != 0 {
+		t.Errorf("faded ranks %+v", ranks)
+	}
+}
+
+func TestPopularityRankChange(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := NewPopularity(time.Hour, time.Minute)
+	p.Add("BTC", start, 2, 0)
+	p.Add("ETH", start, 1, 0)
+	p.Rank(start, 0)
+
+	p.Add("ETH", start, 2, 0)
+	p.Add("DOGE", start, 1, 0)
+	ranks := p.Rank(start.Add(time.Minute), 0)
+	want := []AssetStat{{Name: "ETH", Change: 1}, {Name: "BTC", Change: -1}, {Name: "DOGE", New: true}}
+	for i := range want {
+		if ranks[i].Name != want[i].Name || ranks[i].Change != want[i].Change || ranks[i].New != want[i].New {
+			t.Errorf("rank %d = %+v, want %+v", i+1, ranks[i], want[i])
+		}
+	}
+}
+
+func TestPopularityRanks(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := NewPopularityRanks(time.Minute, time.Hour)
+	r.Add(Activity{Base: "BTC", Quote: "USDT", Kind: "BLOCK_TRADE", Direction: -1, Weight: 1, Amount: 2, Notional: 100}, now)
+	r.Add(Activity{Base: "ETH", Quote: "BTC", Kind: "NEW_KIND", Weight: 1}, now)
+
+	ranks := r.Rank(now, 0)
+	names := func(view int) []string {
+		var names []string
+		for _, s := range ranks[view] {
+			names = append(names, s.Name)
+		}
+		return names
+	}
+	tests := []struct {
+		view int
+		want []string
+	}{
+		{PopularityAssets, []string{"BTC", "ETH"}},
+		{PopularityQuotes, []string{"BTC", "USDT"}},
+		{PopularityNotices, []string{"BLOCK", "NEW_KIND"}},
+		{PopularityBullish, nil},
+		{PopularityBearish, []string{"BTC"}},
+	}
+	for _, tt := range tests {
+		got := names(tt.view)
+		if len(got) != len(tt.want) {
+			t.Errorf("view %d = %v, want %v", tt.view, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("view %d = %v, want %v", tt.view, got, tt.want)
+			}
+		}
+	}
+	if ranks[PopularityAssets][0].AvgVolume != 2 || ranks[PopularityQuotes][1].AvgVolume != 100 {
+		t.Errorf("averages %+v %+v", ranks[PopularityAssets][0], ranks[PopularityQuotes][1])
+	}
+}
@@ -0,0 +1,105 @@
+package data
+
+import (
+	"math"
+)
+
+// ProfileLevel base asset quantity traded inside a price range, split in
+// aggressive buys and sells
+type ProfileLevel struct {
+	Low  float64
+	High float64
+	Buy  float64
+	Sell float64
+}
+
+// Volume returns the quantity traded at the level
+func (l ProfileLevel) Volume() float64 {
+	return l.Buy + l.Sell
+}
+
+// VolumeProfile volume at price histogram of trades
+// Levels are in ascending price order, the point of control is the level
+// of the most volume and the value area the levels around it holding the
+// value area percent of the volume
+type VolumeProfile struct {
+	Levels    []ProfileLevel
+	POC       int
+	ValueLow  int
+	ValueHigh int
+	Trades    int
+}
+
+// NewVolumeProfile returns the profile of trades in bins levels between
+// their lowest and highest price
+func NewVolumeProfile(trades []TradeRecord, bins int, valuearea float64) VolumeProfile {
+	var profile VolumeProfile
+	if len(trades) == 0 || bins < 1 {
+		return profile
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, tr := range trades {
+		low = math.Min(low, tr.Price)
+		high = math.Max(high, tr.Price)
+	}
+	if high == low {
+		bins = 1
+	}
+	step := (high - low) / float64(bins)
+	profile.Levels = make([]ProfileLevel, bins)
+	for i := range profile.Levels {
+		profile.Levels[i].Low = low + float64(i)*step
+		profile.Levels[i].High = low + float64(i+1)*step
+	}
+	profile.Levels[bins-1].High = high
+
+	total := 0.0
+	for _, tr := range trades {
+		i := bins - 1
+		if step > 0 {
+			i = int((tr.Price - low) / step)
+			if i >= bins {
+				i = bins - 1
+			}
+		}
+		// A maker buyer means the seller was the aggressor
+		if tr.IsMaker {
+			profile.Levels[i].Sell += tr.Quantity
+		} else {
+			profile.Levels[i].Buy += tr.Quantity
+		}
+		total += tr.Quantity
+	}
+	profile.Trades = len(trades)
+
+	for i, level := range profile.Levels {
+		if level.Volume() > profile.Levels[profile.POC].Volume() {
+			profile.POC = i
+		}
+	}
+
+	// Grow the value area from the point of control towards the larger
+	// neighbouring level
+	profile.ValueLow, profile.ValueHigh = profile.POC, profile.POC
+	area := profile.Levels[profile.POC].Volume()
+	for area < total*valuearea/100 {
+		below, above := -1.0, -1.0
+		if profile.ValueLow > 0 {
+			below = profile.Levels[profile.ValueLow-1].Volume()
+		}
+		if profile.ValueHigh < bins-1 {
+			above = profile.Levels[profile.ValueHigh+1].Volume()
+		}
+		if below < 0 && above < 0 {
+			break
+		}
+		if above >= below {
+			profile.ValueHigh++
+			area += above
+		} else {
+			profile.ValueLow--
+			area += below
+		}
+	}
+	return profile
+}
@@ -0,0 +1,91 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestNewVolumeProfile(t *testing.T) {
+	trade := func(price, quantity float64, ismaker bool) TradeRecord {
+		return TradeRecord{Price: price, Quantity: quantity, IsMaker: ismaker}
+	}
+	tests := []struct {
+		name      string
+		trades    []TradeRecord
+		bins      int
+		valuearea float64
+		levels    int
+		poc       int
+		low, high int
+	}{
+		{name: "no trades", bins: 4, valuearea: 70},
+		{
+			name:   "one price",
+			trades: []TradeRecord{trade(10, 1, false), trade(10, 2, true)},
+			bins:   4, valuearea: 70, levels: 1,
+		},
+		{
+			// Levels 10-11, 11-12, 12-13, 13-14 with 1, 5, 3, 1
+			name: "grows to the larger neighbour",
+			trades: []TradeRecord{trade(10, 1, false), trade(11.5, 5, false), trade(12.5, 3, true),
+				trade(14, 1, true)},
+			bins: 4, valuearea: 70, levels: 4, poc: 1, low: 1, high: 2,
+		},
+		{
+			// The highest price falls in the last level, not past it
+			name:   "highest price in last level",
+			trades: []TradeRecord{trade(10, 1, false), trade(14, 9, false)},
+			bins:   4, valuearea: 70, levels: 4, poc: 3, low: 3, high: 3,
+		},
+		{
+			// Equal neighbours grow upwards first
+			name: "tie grows above",
+			trades: []TradeRecord{trade(10, 1, false), trade(11.5, 2, false), trade(12.5, 4, false),
+				trade(13.5, 2, false), trade(15, 1, false)},
+			bins: 5, valuearea: 60, levels: 5, poc: 2, low: 2, high: 3,
+		},
+		{
+			name:   "whole range",
+			trades: []TradeRecord{trade(10, 1, false), trade(12, 1, false), trade(14, 1, false)},
+			bins:   2, valuearea: 100, levels: 2, poc: 1, low: 0, high: 1,
+		},
+		{
+			// The value area stops at the edges when levels run out
+			name:   "stops at the edges",
+			trades: []TradeRecord{trade(10, 5, false), trade(14, 5, false)},
+			bins:   2, valuearea: 100.5, levels: 2, poc: 0, low: 0, high: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := NewVolumeProfile(tt.trades, tt.bins, tt.valuearea)
+			if len(profile.Levels) != tt.levels || profile.Trades != len(tt.trades) {
+				t.Fatalf("%d levels of %d trades, want %d of %d", len(profile.Levels), profile.Trades,
+					tt.levels, len(tt.trades))
+			}
+			if profile.POC != tt.poc || profile.ValueLow != tt.low || profile.ValueHigh != tt.high {
+				t.Errorf("POC %d value area %d-%d, want %d %d-%d", profile.POC, profile.ValueLow,
+					profile.ValueHigh, tt.poc, tt.low, tt.high)
+			}
+		})
+	}
+}
+
+func TestVolumeProfileLevels(t *testing.T) {
+	profile := NewVolumeProfile([]TradeRecord{
+		{Price: 100, Quantity: 1, IsMaker: false},
+		{Price: 101, Quantity: 2, IsMaker: true},
+		{Price: 102, Quantity: 4, IsMaker: false},
+	}, 2, 70)
+	want := []ProfileLevel{
+		{Low: 100, High: 101, Buy: 1},
+		{Low: 101, High: 102, Buy: 4, Sell: 2},
+	}
+	for i, level := range profile.Levels {
+		if level != want[i] {
+			t.Errorf("level %d = %+v, want %+v", i, level, want[i])
+		}
+	}
+	if profile.Levels[1].Volume() != 6 {
+		t.Errorf("volume %v, want 6", profile.Levels[1].Volume())
+	}
+}
@@ -0,0 +1,47 @@
+package data
+
+import "time"
+
+// EventRecord stored abnormal event
+type EventRecord struct {
+	Timestamp     time.Time `json:"timestamp"`
+	EventType     string    `json:"eventtype"`
+	NoticeType    string    `json:"noticetype"`
+	Symbol        string    `json:"symbol"`
+	BaseAsset     string    `json:"baseasset"`
+	QuotaAsset    string    `json:"quoteasset"`
+	Volume        float64   `json:"volume"`
+	PriceChange   float64   `json:"pricechange"`
+	Period        string    `json:"period"`
+	SendTimestamp time.Time `json:"sendtimestamp"`
+	Details       string    `json:"details"`
+	Value         float64   `json:"value"` // measure of derived events
+	Ratio         float64   `json:"ratio"`
+}
+
+// DerivedNotices notice types of the events derived by gobit, their measures
+// are kept in Value and Ratio instead of Volume and PriceChange
+var DerivedNotices = []string{"COMPOSITE", "VOLATILITY", "INDICATOR", "NOTICE_RATE"}
+
+// DerivedNotice returns true for the notice types derived by gobit
+func DerivedNotice(noticetype string) bool {
+	for _, derived := range DerivedNotices {
+		if noticetype == derived {
+			return true
+		}
+	}
+	return false
+}
+
+// TradeRecord stored large trade
+type TradeRecord struct {
+	Timestamp      time.Time `json:"timestamp"`
+	EventType      string    `json:"eventtype"`
+	Symbol         string    `json:"symbol"`
+	QuoteAsset     string    `json:"quoteasset"`
+	BaseAsset      string    `json:"baseasset"`
+	Quantity       float64   `json:"quantity"`
+	Price          float64   `json:"price"`
+	TradeTimestamp time.Time `json:"tradetimestamp"`
+	IsMaker        bool      `json:"ismaker"`
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"sort"
+	"time"
+)
+
+// StrengthHorizons performance horizons of the relative strength
+var StrengthHorizons = []time.Duration{time.Hour, 4 * time.Hour, 24 * time.Hour}
+
+// Performance percent price change of an asset over every strength horizon
+type Performance struct {
+	Asset   string
+	Returns []float64
+}
+
+// RelativeStrength performance of an asset and its outperformance of BTC
+// and ETH over every strength horizon, in percent. VsBTC and VsETH are nil
+// when the performance of the benchmark is unknown.
+// Score is the mean known outperformance, positive for real outperformers
+type RelativeStrength struct {
+	Performance
+	VsBTC []float64
+	VsETH []float64
+	Score float64
+}
+
+// relative returns the percent outperformance of a over b
+func relative(a, b float64) float64 {
+	return ((1+a/100)/(1+b/100) - 1) * 100
+}
+
+// RelativeStrengths returns the relative strength of the assets against
+// btc and eth, strongest first. Benchmarks without returns are unknown.
+func RelativeStrengths(assets []Performance, btc, eth Performance) []RelativeStrength {
+	strengths := make([]RelativeStrength, 0, len(assets))
+	for _, a := range assets {
+		s := RelativeStrength{Performance: a}
+		n := 0
+		outperformance := func(benchmark Performance) []float64 {
+			if len(benchmark.Returns) == 0 {
+				return nil
+			}
+			vs := make([]float64, len(a.Returns))
+			for i, r := range a.Returns {
+				if i < len(benchmark.Returns) {
+					vs[i] = relative(r, benchmark.Returns[i])
+					s.Score += vs[i]
+					n++
+				}
+			}
+			return vs
+		}
+		s.VsBTC = outperformance(btc)
+		s.VsETH = outperformance(eth)
+		if n > 0 {
+			s.Score /= float64(n)
+		}
+		strengths = append(strengths, s)
+	}
+	sort.SliceStable(strengths, func(i, j int) bool {
+		return strengths[i].Score > strengths[j].Score
+	})
+	return strengths
+}
@@ -0,0 +1,69 @@
+package data
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRelativeStrengths(t *testing.T) {
+	btc := Performance{Asset: "BTC", Returns: []float64{0, 10, -50}}
+	eth := Performance{Asset: "ETH", Returns: []float64{100, 0, 0}}
+	assets := []Performance{
+		{Asset: "SOL", Returns: []float64{0, 10, -50}},
+		{Asset: "DOGE", Returns: []float64{100, 21, 0}},
+	}
+	tests := []struct {
+		name     string
+		btc, eth Performance
+		want     map[string][3][]float64 // VsBTC, VsETH and Score
+	}{
+		{
+			name: "both benchmarks",
+			btc:  btc, eth: eth,
+			want: map[string][3][]float64{
+				"DOGE": {{100, 10, 100}, {0, 21, 0}, {(210 + 21) / 6.0}},
+				"SOL":  {{0, 0, 0}, {-50, 10, -50}, {-90 / 6.0}},
+			},
+		},
+		{
+			// The missing benchmark stays unknown instead of a 0% return
+			name: "no eth",
+			btc:  btc,
+			want: map[string][3][]float64{
+				"DOGE": {{100, 10, 100}, nil, {70}},
+				"SOL":  {{0, 0, 0}, nil, {0}},
+			},
+		},
+		{
+			name: "no benchmark",
+			want: map[string][3][]float64{
+				"DOGE": {nil, nil, {0}},
+				"SOL":  {nil, nil, {0}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strengths := RelativeStrengths(assets, tt.btc, tt.eth)
+			if len(strengths) != len(assets) {
+				t.Fatalf("%d strengths, want %d", len(strengths), len(assets))
+			}
+			for i, s := range strengths {
+				want := tt.want[s.Asset]
+				for column, got := range [][]float64{s.VsBTC, s.VsETH, {s.Score}} {
+					if (got == nil) != (want[column] == nil) || len(got) != len(want[column]) {
+						t.Fatalf("%s column %d = %v, want %v", s.Asset, column, got, want[column])
+					}
+					for h := range got {
+						if math.Abs(got[h]-want[column][h]) > 1e-9 {
+							t.Errorf("%s column %d = %v, want %v", s.Asset, column, got, want[column])
+						}
+					}
+				}
+				if i > 0 && strengths[i-1].Score < s.Score {
+					t.Errorf("%s before a stronger %s", strengths[i-1].Asset, s.Asset)
+				}
+			}
+		})
+	}
+}
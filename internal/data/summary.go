@@ -0,0 +1,184 @@
+package data
+
+import (
+	"sort"
+	"time"
+)
+
+// Rows of every summary list
+const summaryrows = 10
+
+// Slots of the maker/taker flow of the summary period
+const summaryslots = 12
+
+// NoticeCount number of notices of a type and level
+type NoticeCount struct {
+	NoticeType string
+	EventType  string
+	Count      int
+}
+
+// Mover largest price change notice of a pair, in percent
+type Mover struct {
+	Symbol string
+	Period string
+	Time   time.Time
+	Change float64
+}
+
+// LargeTrade stored trade and its notional in default quote
+type LargeTrade struct {
+	TradeRecord
+	Notional float64
+}
+
+// PairActivity stored trades of a pair, notionals in default quote
+type PairActivity struct {
+	Symbol string
+	Trades int
+	Maker  float64
+	Taker  float64
+}
+
+// FlowSlot maker and taker notional of the stored trades of a time slot
+type FlowSlot struct {
+	From  time.Time
+	Maker float64
+	Taker float64
+}
+
+// Summary overview of the stored events and trades of a period
+type Summary struct {
+	From    time.Time
+	To      time.Time
+	Events  int
+	Trades  int
+	Notices []NoticeCount
+	Gainers []Mover
+	Losers  []Mover
+	Largest []LargeTrade
+	Active  []PairActivity
+	Flow    []FlowSlot
+}
+
+// NewSummary returns the summary of events and trades between from and to,
+// zero times are the first record and now. Rate converts quote assets
+// into the default quote.
+func NewSummary(from, to time.Time, events []EventRecord, trades []TradeRecord, rate func(quote string) float64) Summary {
+	s := Summary{From: from, To: to, Events: len(events), Trades: len(trades)}
+	if s.To.IsZero() {
+		s.To = time.Now()
+	}
+	if s.From.IsZero() {
+		s.From = s.To
+		for _, ev := range events {
+			if ev.Timestamp.Before(s.From) {
+				s.From = ev.Timestamp
+			}
+		}
+		for _, tr := range trades {
+			if tr.Timestamp.Before(s.From) {
+				s.From = tr.Timestamp
+			}
+		}
+	}
+
+	// Notice counts and the largest move of every notified pair
+	counts := make(map[[2]string]int)
+	gainers := make(map[string]Mover)
+	losers := make(map[string]Mover)
+	for _, ev := range events {
+		counts[[2]string{ev.NoticeType, ev.EventType}]++
+		if ev.NoticeType != "PRICE_CHANGE" {
+			continue
+		}
+		m := Mover{Symbol: ev.Symbol, Period: ev.Period, Time: ev.Timestamp, Change: ev.PriceChange * 100}
+		if m.Change > 0 && m.Change > gainers[ev.Symbol].Change {
+			gainers[ev.Symbol] = m
+		} else if m.Change < 0 && m.Change < losers[ev.Symbol].Change {
+			losers[ev.Symbol] = m
+		}
+	}
+	for key, n := range counts {
+		s.Notices = append(s.Notices, NoticeCount{NoticeType: key[0], EventType: key[1], Count: n})
+	}
+	sort.Slice(s.Notices, func(i, j int) bool {
+		a, b := s.Notices[i], s.Notices[j]
+		if a.NoticeType != b.NoticeType {
+			return a.NoticeType < b.NoticeType
+		}
+		return a.EventType < b.EventType
+	})
+	s.Gainers = movers(gainers, func(a, b Mover) bool { return a.Change > b.Change })
+	s.Losers = movers(losers, func(a, b Mover) bool { return a.Change < b.Change })
+
+	// Largest trades, most active pairs and the maker/taker flow
+	active := make(map[string]*PairActivity)
+	s.Flow = make([]FlowSlot, summaryslots)
+	slot := s.To.Sub(s.From) / summaryslots
+	for i := range s.Flow {
+		s.Flow[i].From = s.From.Add(time.Duration(i) * slot)
+	}
+	for _, tr := range trades {
+		notional := tr.Price * tr.Quantity * rate(tr.QuoteAsset)
+		s.Largest = append(s.Largest, LargeTrade{TradeRecord: tr, Notional: notional})
+		a, ok := active[tr.Symbol]
+		if !ok {
+			a = &PairActivity{Symbol: tr.Symbol}
+			active[tr.Symbol] = a
+		}
+		a.Trades++
+		i := 0
+		if slot > 0 {
+			i = int(tr.Timestamp.Sub(s.From) / slot)
+		}
+		if i < 0 || i >= summaryslots {
+			i = summaryslots - 1
+		}
+		// A maker buyer means the seller was the aggressor
+		if tr.IsMaker {
+			a.Maker += notional
+			s.Flow[i].Maker += notional
+		} else {
+			a.Taker += notional
+			s.Flow[i].Taker += notional
+		}
+	}
+	sort.SliceStable(s.Largest, func(i, j int) bool {
+		return s.Largest[i].Notional > s.Largest[j].Notional
+	})
+	if len(s.Largest) > summaryrows {
+		s.Largest = s.Largest[:summaryrows]
+	}
+	for _, a := range active {
+		s.Active = append(s.Active, *a)
+	}
+	sort.Slice(s.Active, func(i, j int) bool {
+		if s.Active[i].Trades != s.Active[j].Trades {
+			return s.Active[i].Trades > s.Active[j].Trades
+		}
+		return s.Active[i].Symbol < s.Active[j].Symbol
+	})
+	if len(s.Active) > summaryrows {
+		s.Active = s.Active[:summaryrows]
+	}
+	return s
+}
+
+// movers returns the first summary rows movers in order of less
+func movers(m map[string]Mover, less func(a, b Mover) bool) []Mover {
+	list := make([]Mover, 0, len(m))
+	for _, mover := range m {
+		list = append(list, mover)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Change != list[j].Change {
+			return less(list[i], list[j])
+		}
+		return list[i].Symbol < list[j].Symbol
+	})
+	if len(list) > summaryrows {
+		list = list[:summaryrows]
+	}
+	return list
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSummary(t *testing.T) {
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(12 * time.Hour)
+	at := func(hours float64) time.Time { return from.Add(time.Duration(hours * float64(time.Hour))) }
+	events := []EventRecord{
+		{Timestamp: at(1), NoticeType: "PRICE_CHANGE", EventType: "UP_1", Symbol: "AUSDT", PriceChange: 0.05},
+		{Timestamp: at(2), NoticeType: "PRICE_CHANGE", EventType: "UP_2", Symbol: "AUSDT", PriceChange: 0.08},
+		{Timestamp: at(3), NoticeType: "PRICE_CHANGE", EventType: "UP_1", Symbol: "BUSDT", PriceChange: 0.06},
+		{Timestamp: at(4), NoticeType: "PRICE_CHANGE", EventType: "DOWN_1", Symbol: "AUSDT", PriceChange: -0.03},
+		{Timestamp: at(5), NoticeType: "BLOCK_TRADE", EventType: "BLOCK_TRADES_BUY", Symbol: "CUSDT", PriceChange: 0.5},
+	}
+	trades := []TradeRecord{
+		{Timestamp: at(0.5), Symbol: "ETHBTC", QuoteAsset: "BTC", Price: 0.05, Quantity: 10},
+		{Timestamp: at(6.5), Symbol: "BTCUSDT", QuoteAsset: "USDT", Price: 20000, Quantity: 1, IsMaker: true},
+		{Timestamp: at(11.9), Symbol: "ETHBTC", QuoteAsset: "BTC", Price: 0.05, Quantity: 1},
+		{Timestamp: at(13), Symbol: "ETHBTC", QuoteAsset: "BTC", Price: 0.05, Quantity: 1}, // after to, last slot
+	}
+	rate := func(quote string) float64 {
+		if quote == "BTC" {
+			return 20000
+		}
+		return 1
+	}
+
+	s := NewSummary(from, to, events, trades, rate)
+	if s.Events != 5 || s.Trades != 4 {
+		t.Errorf("%d events %d trades, want 5 and 4", s.Events, s.Trades)
+	}
+	notices := []NoticeCount{
+		{"BLOCK_TRADE", "BLOCK_TRADES_BUY", 1},
+		{"PRICE_CHANGE", "DOWN_1", 1},
+		{"PRICE_CHANGE", "UP_1", 2},
+		{"PRICE_CHANGE", "UP_2", 1},
+	}
+	if len(s.Notices) != len(notices) {
+		t.Fatalf("notices %+v, want %+v", s.Notices, notices)
+	}
+	for i := range notices {
+		if s.Notices[i] != notices[i] {
+			t.Errorf("notice %d = %+v, want %+v", i, s.Notices[i], notices[i])
+		}
+	}
+	if len(s.Gainers) != 2 || s.Gainers[0].Symbol != "AUSDT" || s.Gainers[0].Change != 8 || s.Gainers[1].Symbol != "BUSDT" {
+		t.Errorf("gainers %+v, want AUSDT +8%% then BUSDT", s.Gainers)
+	}
+	if len(s.Losers) != 1 || s.Losers[0].Symbol != "AUSDT" || s.Losers[0].Change != -3 {
+		t.Errorf("losers %+v, want AUSDT -3%%", s.Losers)
+	}
+	if s.Largest[0].Symbol != "BTCUSDT" || s.Largest[0].Notional != 20000 || s.Largest[1].Notional != 10000 {
+		t.Errorf("largest %+v", s.Largest)
+	}
+	if len(s.Active) != 2 || s.Active[0].Symbol != "ETHBTC" || s.Active[0].Trades != 3 || s.Active[0].Taker != 12000 {
+		t.Errorf("active %+v", s.Active)
+	}
+	flow := map[int]FlowSlot{0: {Taker: 10000}, 6: {Maker: 20000}, 11: {Taker: 2000}}
+	for i, slot := range s.Flow {
+		if !slot.From.Equal(at(float64(i))) || slot.Maker != flow[i].Maker || slot.Taker != flow[i].Taker {
+			t.Errorf("flow slot %d = %+v, want %+v", i, slot, flow[i])
+		}
+	}
+}
+
+func TestNewSummaryFrom(t *testing.T) {
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := first.Add(time.Hour)
+	s := NewSummary(time.Time{}, to,
+		[]EventRecord{{Timestamp: first.Add(time.Minute)}},
+		[]TradeRecord{{Timestamp: first}},
+		func(string) float64 { return 1 })
+	if !s.From.Equal(first) || !s.To.Equal(to) {
+		t.Errorf("period %v - %v, want %v - %v", s.From, s.To, first, to)
+	}
+	if s := NewSummary(time.Time{}, time.Time{}, nil, nil, nil); s.To.IsZero() || !s.From.Equal(s.To) {
+		t.Errorf("empty period %v - %v", s.From, s.To)
+	}
+}
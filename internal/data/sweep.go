@@ -0,0 +1,87 @@
+package data
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Sweep same side aggTrades of a symbol close in time and price,
+// like a large order split across the book
+type Sweep struct {
+	Symbol   string
+	IsMaker  bool
+	First    uint64 // first trade time, epoch ms
+	Last     uint64 // last trade time, epoch ms
+	Received uint64 // local receive time of the last trade, epoch ms
+	Quantity float64
+	Amount   float64 // notional in quote asset
+	Count    int
+	Low      float64
+	High     float64
+}
+
+// Sweeps open sweep per symbol
+type Sweeps struct {
+	sync.Mutex
+	open        map[string]*Sweep
+	window      uint64  // most ms between trades of a sweep
+	pricewindow float64 // most price range of a sweep, as a fraction
+}
+
+// NewSweeps returns sweeps of trades at most window apart, inside a
+// price range of pricewindow percent
+func NewSweeps(window time.Duration, pricewindow float64) *Sweeps {
+	return &Sweeps{
+		open:        make(map[string]*Sweep),
+		window:      uint64(window / time.Millisecond),
+		pricewindow: pricewindow / 100,
+	}
+}
+
+// Add returns the sweep closed by the trade, if any
+// Adds a trade to the open sweep of symbol or starts a new one
+func (s *Sweeps) Add(symbol string, ismaker bool, price, quantity float64, tradetime, received uint64) *Sweep {
+	s.Lock()
+	defer s.Unlock()
+	sweep := s.open[symbol]
+	if sweep != nil && sweep.IsMaker == ismaker && tradetime <= sweep.Last+s.window &&
+		math.Max(sweep.High, price)/math.Min(sweep.Low, price)-1 <= s.pricewindow {
+		sweep.Last = tradetime
+		sweep.Received = received
+		sweep.Quantity += quantity
+		sweep.Amount += price * quantity
+		sweep.Count++
+		sweep.Low = math.Min(sweep.Low, price)
+		sweep.High = math.Max(sweep.High, price)
+		return nil
+	}
+	s.open[symbol] = &Sweep{
+		Symbol:   symbol,
+		IsMaker:  ismaker,
+		First:    tradetime,
+		Last:     tradetime,
+		Received: received,
+		Quantity: quantity,
+		Amount:   price * quantity,
+		Count:    1,
+		Low:      price,
+		High:     price,
+	}
+	return sweep
+}
+
+// Expire returns the sweeps closed at now
+// Closes the sweeps without trades received during the window before now
+func (s *Sweeps) Expire(now uint64) []*Sweep {
+	s.Lock()
+	defer s.Unlock()
+	var closed []*Sweep
+	for symbol, sweep := range s.open {
+		if sweep.Received+s.window < now {
+			closed = append(closed, sweep)
+			delete(s.open, symbol)
+		}
+	}
+	return closed
+}
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSweeps(t *testing.T) {
+	type trade struct {
+		ismaker  bool
+		price    float64
+		quantity float64
+		time     uint64
+	}
+	tests := []struct {
+		name   string
+		trades []trade
+		closed []int // trades in every sweep closed by a new trade
+		open   int   // trades of the sweep left open
+	}{
+		{
+			name:   "one sweep",
+			trades: []trade{{false, 100, 1, 0}, {false, 100.05, 2, 50}, {false, 100.1, 3, 100}},
+			open:   3,
+		},
+		{
+			name:   "side change",
+			trades: []trade{{false, 100, 1, 0}, {false, 100, 1, 10}, {true, 100, 1, 20}},
+			closed: []int{2},
+			open:   1,
+		},
+		{
+			name:   "time gap",
+			trades: []trade{{false, 100, 1, 0}, {false, 100, 1, 100}, {false, 100, 1, 201}},
+			closed: []int{2},
+			open:   1,
+		},
+		{
+			name:   "price range",
+			trades: []trade{{false, 100, 1, 0}, {false, 100.2, 1, 10}, {false, 100.3, 1, 20}},
+			closed: []int{2},
+			open:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sweeps := NewSweeps(100*time.Millisecond, 0.25)
+			var closed []int
+			for _, tr := range tt.trades {
+				if sweep := sweeps.Add("BTCUSDT", tr.ismaker, tr.price, tr.quantity, tr.time, tr.time); sweep != nil {
+					closed = append(closed, sweep.Count)
+				}
+			}
+			if len(closed) != len(tt.closed) {
+				t.Fatalf("closed sweeps %v, want %v", closed, tt.closed)
+			}
+			for i := range closed {
+				if closed[i] != tt.closed[i] {
+					t.Errorf("closed sweeps %v, want %v", closed, tt.closed)
+				}
+			}
+			if open := sweeps.open["BTCUSDT"]; open == nil || open.Count != tt.open {
+				t.Errorf("open sweep %+v, want %d trades", open, tt.open)
+			}
+		})
+	}
+}
+
+func TestSweepTotals(t *testing.T) {
+	sweeps := NewSweeps(time.Second, 1)
+	sweeps.Add("ETHUSDT", true, 10, 1, 1000, 1005)
+	sweeps.Add("ETHUSDT", true, 10.05, 2, 1200, 1210)
+	sweeps.Add("ETHUSDT", true, 9.98, 1, 1500, 1520)
+
+	if closed := sweeps.Expire(2520); len(closed) != 0 {
+		t.Fatalf("expired %d sweeps inside the window", len(closed))
+	}
+	closed := sweeps.Expire(2521)
+	if len(closed) != 1 {
+		t.Fatalf("expired %d sweeps, want 1", len(closed))
+	}
+	want := Sweep{Symbol: "ETHUSDT", IsMaker: true, First: 1000, Last: 1500, Received: 1520,
+		Quantity: 4, Amount: 10 + 20.1 + 9.98, Count: 3, Low: 9.98, High: 10.05}
+	got := *closed[0]
+	if got.Amount-want.Amount > 1e-9 || want.Amount-got.Amount > 1e-9 {
+		t.Errorf("amount %v, want %v", got.Amount, want.Amount)
+	}
+	got.Amount = want.Amount
+	if got != want {
+		t.Errorf("sweep %+v, want %+v", got, want)
+	}
+	if len(sweeps.Expire(10000)) != 0 {
+		t.Error("expired sweep kept open")
+	}
+}
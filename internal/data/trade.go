@@ -1,22 +1,331 @@
 package data
 
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
 // AssetStat Status Data
 type AssetStat struct {
 	Name      string
 	Momentum  float64
 	AvgVolume float64
+	Change    int  // ranks risen since the previous ranking
+	New       bool // not in the previous ranking
+	Spike     bool // notice rate far above its baseline
 }
 
 // Symbol Asset Data
 type Symbol struct {
-	Symbol	string
-	BaseAsset	string
-	QuoteAsset	string
+	Symbol     string
+	BaseAsset  string
+	QuoteAsset string
+}
+
+// TradeBucket one second of trade flow, notional in default quote,
+// delta is aggressive buy minus aggressive sell base quantity
+type TradeBucket struct {
+	Second int64
+	Maker  float64
+	Taker  float64
+	Delta  float64
+	Number uint64
+	VWAP   VWAP
+}
+
+// VWAP volume weighted average price sums
+type VWAP struct {
+	PV    float64 // price * quantity
+	V     float64 // quantity
+	P2V   float64 // price^2 * quantity
+	N     uint64  // number of trades
+	Since time.Time
+}
+
+// Fewest trades before the VWAP bands are considered meaningful
+const minvwaptrades = 20
+
+// Add - Adds a trade to the sums
+func (v *VWAP) Add(price, quantity float64) {
+	v.PV += price * quantity
+	v.V += quantity
+	v.P2V += price * price * quantity
+	v.N++
+}
+
+// Price returns the volume weighted average price
+func (v VWAP) Price() float64 {
+	if v.V == 0 {
+		return 0
+	}
+	return v.PV / v.V
+}
+
+// Deviation returns the volume weighted standard deviation of price
+func (v VWAP) Deviation() float64 {
+	if v.V == 0 {
+		return 0
+	}
+	vwap := v.PV / v.V
+	return math.Sqrt(math.Max(0, v.P2V/v.V-vwap*vwap))
+}
+
+// Sigmas returns the distance of price from the VWAP in deviations,
+// false until enough trades are seen
+func (v VWAP) Sigmas(price float64) (float64, bool) {
+	deviation := v.Deviation()
+	if deviation == 0 || v.N < minvwaptrades {
+		return 0, false
+	}
+	return (price - v.Price()) / deviation, true
 }
 
 // TradeStat statistics data
+// Rolling trade flow kept in one bucket per second, the cumulative
+// volume delta and VWAP since creation, an optional anchored VWAP and
+// the distribution of the last trade notionals of pairs
 type TradeStat struct {
-	Taker	float64
-	Maker	float64
-	Number	uint64
+	sync.Mutex
+	Buckets   []TradeBucket
+	CVD       float64
+	Session   VWAP
+	Anchored  *VWAP
+	Notionals *Distribution
+
+	limit       float64 // large trade threshold in use, in default quote
+	limitsource string
+}
+
+// NewTradeStat returns trade statistics covering span
+func NewTradeStat(span time.Duration) *TradeStat {
+	seconds := int(span / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &TradeStat{
+		Buckets: make([]TradeBucket, seconds),
+		Session: VWAP{Since: time.Now()},
+	}
+}
+
+// Add - Adds a trade at time t, rate converts its quote to the default quote
+func (s *TradeStat) Add(t time.Time, ismaker bool, price, quantity, rate float64) {
+	s.Lock()
+	defer s.Unlock()
+	second := t.Unix()
+	b := &s.Buckets[second%int64(len(s.Buckets))]
+	if b.Second != second {
+		*b = TradeBucket{Second: second}
+	}
+	// A maker buyer means the seller was the aggressor
+	if ismaker {
+		b.Maker += price * quantity * rate
+		b.Delta -= quantity
+		s.CVD -= quantity
+	} else {
+		b.Taker += price * quantity * rate
+		b.Delta += quantity
+		s.CVD += quantity
+	}
+	b.Number++
+	if s.Notionals != nil {
+		s.Notionals.Add(price * quantity * rate)
+	}
+	b.VWAP.Add(price, quantity)
+	s.Session.Add(price, quantity)
+	if s.Anchored != nil {
+		s.Anchored.Add(price, quantity)
+	}
+}
+
+// Anchor - Starts an anchored VWAP at time t, trades before t still kept
+// in the buckets are added, older anchors start from the oldest bucket
+func (s *TradeStat) Anchor(t time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	anchor := &VWAP{Since: t}
+	oldest := int64(0)
+	for _, b := range s.Buckets {
+		if b.Number == 0 || b.Second < t.Unix() {
+			continue
+		}
+		anchor.PV += b.VWAP.PV
+		anchor.V += b.VWAP.V
+		anchor.P2V += b.VWAP.P2V
+		anchor.N += b.VWAP.N
+		if oldest == 0 || b.Second < oldest {
+			oldest = b.Second
+		}
+	}
+	kept := time.Now().Add(-time.Duration(len(s.Buckets)) * time.Second)
+	if t.Before(kept) && oldest != 0 {
+		anchor.Since = time.Unix(oldest, 0)
+	}
+	s.Anchored = anchor
+}
+
+// VWAPs returns copies of the session and anchored VWAP, anchored may be nil
+func (s *TradeStat) VWAPs() (session VWAP, anchored *VWAP) {
+	s.Lock()
+	defer s.Unlock()
+	if s.Anchored != nil {
+		a := *s.Anchored
+		anchored = &a
+	}
+	return s.Session, anchored
+}
+
+// NotionalThreshold returns the notional percentile (0-100) of the last trades,
+// or the z-score notional when percentile is zero, and the sample count
+func (s *TradeStat) NotionalThreshold(percentile, zscore float64) (float64, int) {
+	s.Lock()
+	defer s.Unlock()
+	if s.Notionals == nil {
+		return 0, 0
+	}
+	if percentile > 0 {
+		return s.Notionals.Percentile(percentile), s.Notionals.Len()
+	}
+	return s.Notionals.ZScore(zscore), s.Notionals.Len()
+}
+
+// SetLimit - Keeps the large trade threshold last applied and its source
+func (s *TradeStat) SetLimit(limit float64, source string) {
+	s.Lock()
+	defer s.Unlock()
+	s.limit, s.limitsource = limit, source
+}
+
+// Limit returns the large trade threshold last applied and its source
+func (s *TradeStat) Limit() (float64, string) {
+	s.Lock()
+	defer s.Unlock()
+	return s.limit, s.limitsource
+}
+
+// Sigmas returns the distance of price from the anchored VWAP when set,
+// otherwise from the session VWAP
+func (s *TradeStat) Sigmas(price float64) (float64, bool) {
+	session, anchored := s.VWAPs()
+	if anchored != nil {
+		return anchored.Sigmas(price)
+	}
+	return session.Sigmas(price)
+}
+
+// CumulativeDelta returns the volume delta since creation
+func (s *TradeStat) CumulativeDelta() float64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.CVD
+}
+
+// DeltaSeries - Cumulative volume delta at the end of each of the points
+// slots of the last window up to now
+func (s *TradeStat) DeltaSeries(now time.Time, window time.Duration, points int) []float64 {
+	s.Lock()
+	defer s.Unlock()
+	series := make([]float64, points)
+	last := now.Unix()
+	seconds := int64(window / time.Second)
+	first := last - seconds
+	total := 0.0
+	for _, b := range s.Buckets {
+		if b.Second > first && b.Second <= last {
+			slot := int((b.Second - first - 1) * int64(points) / seconds)
+			series[slot] += b.Delta
+			total += b.Delta
+		}
+	}
+	// Accumulate starting from the delta before the window
+	cvd := s.CVD - total
+	for i := range series {
+		cvd += series[i]
+		series[i] = cvd
+	}
+	return series
+}
+
+// Window - Sums the trade flow of the last window up to now
+func (s *TradeStat) Window(now time.Time, window time.Duration) (sum TradeBucket) {
+	s.Lock()
+	defer s.Unlock()
+	last := now.Unix()
+	first := last - int64(window/time.Second)
+	for _, b := range s.Buckets {
+		if b.Second > first && b.Second <= last {
+			sum.Maker += b.Maker
+			sum.Taker += b.Taker
+			sum.Delta += b.Delta
+			sum.Number += b.Number
+		}
+	}
+	sum.Second = last
+	return
+}
+
+// TradeStats aggregate and per symbol trade statistics
+type TradeStats struct {
+	sync.Mutex
+	All     *TradeStat
+	Pairs   map[string]*TradeStat
+	span    time.Duration
+	samples int
+}
+
+// NewTradeStats returns aggregate and per symbol statistics covering span,
+// pairs keep the notional distribution of their last samples trades
+func NewTradeStats(span time.Duration, samples int) *TradeStats {
+	return &TradeStats{
+		All:     NewTradeStat(span),
+		Pairs:   make(map[string]*TradeStat),
+		span:    span,
+		samples: samples,
+	}
+}
+
+// Add - Adds a trade of symbol at time t
+func (s *TradeStats) Add(symbol string, t time.Time, ismaker bool, price, quantity, rate float64) {
+	s.All.Add(t, ismaker, price, quantity, rate)
+	s.Lock()
+	pair, ok := s.Pairs[symbol]
+	if !ok {
+		pair = NewTradeStat(s.span)
+		pair.Notionals = NewDistribution(s.samples)
+		s.Pairs[symbol] = pair
+	}
+	s.Unlock()
+	pair.Add(t, ismaker, price, quantity, rate)
+}
+
+// Pair returns the statistics of symbol or nil
+func (s *TradeStats) Pair(symbol string) *TradeStat {
+	s.Lock()
+	defer s.Unlock()
+	return s.Pairs[symbol]
+}
+
+// Symbols returns the sorted symbols with statistics
+func (s *TradeStats) Symbols() []string {
+	s.Lock()
+	defer s.Unlock()
+	symbols := make([]string, 0, len(s.Pairs))
+	for symbol := range s.Pairs {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Retain - Drops the symbols not kept, like unsubscribed pairs
+func (s *TradeStats) Retain(keep func(symbol string) bool) {
+	s.Lock()
+	defer s.Unlock()
+	for symbol := range s.Pairs {
+		if !keep(symbol) {
+			delete(s.Pairs, symbol)
+		}
+	}
 }
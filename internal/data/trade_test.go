@@ -0,0 +1,98 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVWAPSigmas(t *testing.T) {
+	tests := []struct {
+		name   string
+		trades int
+		price  float64
+		want   float64
+		ok     bool
+	}{
+		{name: "too few trades", trades: minvwaptrades - 1, price: 12},
+		// Half the quantity at 9 and half at 11, VWAP 10 deviation 1
+		{name: "above", trades: minvwaptrades, price: 12, want: 2, ok: true},
+		{name: "below", trades: minvwaptrades, price: 9.5, want: -0.5, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v VWAP
+			for i := 0; i < tt.trades; i++ {
+				v.Add(9+float64(i%2)*2, 1)
+			}
+			sigmas, ok := v.Sigmas(tt.price)
+			if ok != tt.ok || math.Abs(sigmas-tt.want) > 1e-9 {
+				t.Errorf("Sigmas(%v) = %v %v, want %v %v", tt.price, sigmas, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	var flat VWAP
+	for i := 0; i < minvwaptrades; i++ {
+		flat.Add(10, 1)
+	}
+	if _, ok := flat.Sigmas(11); ok {
+		t.Error("sigmas of a VWAP without deviation")
+	}
+}
+
+func TestTradeStatWindow(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewTradeStat(time.Minute)
+	s.Add(now.Add(-90*time.Second), false, 10, 5, 1) // overwritten bucket
+	s.Add(now.Add(-30*time.Second), false, 10, 2, 2)
+	s.Add(now.Add(-30*time.Second), true, 10, 1, 2)
+	s.Add(now.Add(-5*time.Second), true, 20, 1, 2)
+
+	tests := []struct {
+		window time.Duration
+		want   TradeBucket
+	}{
+		{10 * time.Second, TradeBucket{Second: now.Unix(), Maker: 40, Delta: -1, Number: 1}},
+		{time.Minute, TradeBucket{Second: now.Unix(), Maker: 60, Taker: 40, Delta: 0, Number: 3}},
+	}
+	for _, tt := range tests {
+		if got := s.Window(now, tt.window); got != tt.want {
+			t.Errorf("Window(%v) = %+v, want %+v", tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestTradeStatDelta(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewTradeStat(time.Minute)
+	s.Add(now.Add(-90*time.Second), false, 10, 5, 1) // overwritten bucket
+	s.Add(now.Add(-30*time.Second), false, 10, 2, 2)
+	s.Add(now.Add(-30*time.Second), true, 10, 1, 2)
+	s.Add(now.Add(-5*time.Second), true, 20, 1, 2)
+
+	if s.CumulativeDelta() != 5 {
+		t.Errorf("CVD %v, want 5", s.CumulativeDelta())
+	}
+	series := s.DeltaSeries(now, time.Minute, 2)
+	if series[0] != 6 || series[1] != 5 {
+		t.Errorf("DeltaSeries = %v, want [6 5]", series)
+	}
+}
+
+func TestTradeStatAnchor(t *testing.T) {
+	now := time.Now()
+	s := NewTradeStat(time.Minute)
+	s.Add(now.Add(-20*time.Second), false, 10, 1, 1)
+	s.Add(now.Add(-10*time.Second), false, 20, 1, 1)
+	s.Anchor(now.Add(-15 * time.Second))
+	s.Add(now, false, 30, 1, 1)
+
+	session, anchored := s.VWAPs()
+	if session.Price() != 20 || session.N != 3 {
+		t.Errorf("session VWAP %v of %d trades, want 20 of 3", session.Price(), session.N)
+	}
+	if anchored == nil || anchored.Price() != 25 || anchored.N != 2 {
+		t.Errorf("anchored VWAP %+v, want 25 of 2 trades", anchored)
+	}
+}
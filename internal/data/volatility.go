@@ -0,0 +1,222 @@
+package data
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Minutes of closes kept per pair, the most klines of one request
+const VolatilityMinutes = 1000
+
+// Fewest samples of a window before its cone is considered meaningful
+const minconesamples = 10
+
+// One minute returns per year, to annualize realized volatility
+const minutesperyear = 365 * 24 * 60
+
+// VolatilityCone realized volatility of a window, annualized percent
+// Low, Median and High are the 10th, 50th and 90th percentile of the
+// window over the kept closes, Current is the last window
+type VolatilityCone struct {
+	Window  time.Duration
+	Low     float64
+	Median  float64
+	High    float64
+	Current float64
+	Samples int
+}
+
+// Volatility one minute closes of a pair and its volatility regime,
+// 1 above and -1 below the range of the shortest window
+type Volatility struct {
+	sync.Mutex
+	Closes []float64 // oldest first
+	last   int64     // minute of the last close
+	regime int
+}
+
+// Add - Adds a trade price at time t, the last price of a minute is its
+// close, minutes without trades repeat the previous close
+func (v *Volatility) Add(t time.Time, price float64) {
+	v.Lock()
+	defer v.Unlock()
+	minute := t.Unix() / 60
+	switch {
+	case len(v.Closes) == 0:
+		v.Closes = append(v.Closes, price)
+	case minute == v.last:
+		v.Closes[len(v.Closes)-1] = price
+	case minute > v.last:
+		gap := minute - v.last - 1
+		if gap > VolatilityMinutes {
+			gap = VolatilityMinutes
+		}
+		previous := v.Closes[len(v.Closes)-1]
+		for i := int64(0); i < gap; i++ {
+			v.Closes = append(v.Closes, previous)
+		}
+		v.Closes = append(v.Closes, price)
+	default:
+		return
+	}
+	v.last = minute
+	v.trim()
+}
+
+// Seed - Adds the closes of consecutive minutes from start before the
+// kept closes, like klines fetched after the first trades
+func (v *Volatility) Seed(start time.Time, closes []float64) {
+	v.Lock()
+	defer v.Unlock()
+	first := start.Unix() / 60
+	if len(v.Closes) == 0 {
+		v.Closes = append(v.Closes, closes...)
+		v.last = first + int64(len(closes)) - 1
+		v.trim()
+		return
+	}
+	kept := v.last - int64(len(v.Closes)) + 1
+	n := int(kept - first)
+	if n <= 0 {
+		return
+	}
+	if n > len(closes) {
+		n = len(closes)
+	}
+	v.Closes = append(append([]float64{}, closes[:n]...), v.Closes...)
+	v.trim()
+}
+
+// trim - Drops the oldest closes over VolatilityMinutes
+func (v *Volatility) trim() {
+	if len(v.Closes) > VolatilityMinutes {
+		v.Closes = append(v.Closes[:0], v.Closes[len(v.Closes)-VolatilityMinutes:]...)
+	}
+}
+
+// RealizedVolatility returns the annualized percent volatility of the one
+// minute log returns of closes
+func RealizedVolatility(closes []float64) float64 {
+	if len(closes) < 2 {
+		return 0
+	}
+	sum := 0.0
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 || closes[i] <= 0 {
+			continue
+		}
+		r := math.Log(closes[i] / closes[i-1])
+		sum += r * r
+	}
+	return math.Sqrt(sum/float64(len(closes)-1)*minutesperyear) * 100
+}
+
+// Cone returns the volatility cone of every window with enough closes
+func (v *Volatility) Cone(windows []time.Duration) []VolatilityCone {
+	v.Lock()
+	defer v.Unlock()
+	cones := make([]VolatilityCone, 0, len(windows))
+	for _, window := range windows {
+		n := int(window / time.Minute)
+		if n < 1 || len(v.Closes) < n+1 {
+			continue
+		}
+		// Rolling windows, stepping a quarter window
+		step := n / 4
+		if step < 1 {
+			step = 1
+		}
+		samples := make([]float64, 0)
+		for end := len(v.Closes); end-n-1 >= 0; end -= step {
+			samples = append(samples, RealizedVolatility(v.Closes[end-n-1:end]))
+		}
+		cone := VolatilityCone{Window: window, Current: samples[0], Samples: len(samples)}
+		sort.Float64s(samples)
+		cone.Low = samples[len(samples)/10]
+		cone.Median = samples[len(samples)/2]
+		cone.High = samples[len(samples)*9/10]
+		cones = append(cones, cone)
+	}
+	return cones
+}
+
+// Regime returns the cone of the shortest window and the volatility regime
+// when it changes, 1 when the current volatility breaks above the range
+// of the window and -1 below it. The regime resets inside the range.
+func (v *Volatility) Regime(windows []time.Duration) (VolatilityCone, int) {
+	cones := v.Cone(windows[:1])
+	if len(cones) == 0 || cones[0].Samples < minconesamples {
+		return VolatilityCone{}, 0
+	}
+	cone := cones[0]
+	regime := 0
+	if cone.Current > cone.High {
+		regime = 1
+	} else if cone.Current < cone.Low {
+		regime = -1
+	}
+	v.Lock()
+	defer v.Unlock()
+	if regime == v.regime {
+		return cone, 0
+	}
+	v.regime = regime
+	return cone, regime
+}
+
+// Volatilities one minute closes per symbol
+type Volatilities struct {
+	sync.Mutex
+	Pairs map[string]*Volatility
+}
+
+// NewVolatilities returns empty volatilities
+func NewVolatilities() *Volatilities {
+	return &Volatilities{Pairs: make(map[string]*Volatility)}
+}
+
+// Add - Adds a trade price of symbol at time t, returns true for symbols
+// seen the first time
+func (s *Volatilities) Add(symbol string, t time.Time, price float64) bool {
+	s.Lock()
+	pair, ok := s.Pairs[symbol]
+	if !ok {
+		pair = &Volatility{}
+		s.Pairs[symbol] = pair
+	}
+	s.Unlock()
+	pair.Add(t, price)
+	return !ok
+}
+
+// Pair returns the volatility of symbol or nil
+func (s *Volatilities) Pair(symbol string) *Volatility {
+	s.Lock()
+	defer s.Unlock()
+	return s.Pairs[symbol]
+}
+
+// Symbols returns the sorted symbols with volatility
+func (s *Volatilities) Symbols() []string {
+	s.Lock()
+	defer s.Unlock()
+	symbols := make([]string, 0, len(s.Pairs))
+	for symbol := range s.Pairs {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Retain - Drops the symbols not kept, like unsubscribed pairs
+func (s *Volatilities) Retain(keep func(symbol string) bool) {
+	s.Lock()
+	defer s.Unlock()
+	for symbol := range s.Pairs {
+		if !keep(symbol) {
+			delete(s.Pairs, symbol)
+		}
+	}
+}
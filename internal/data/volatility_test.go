@@ -0,0 +1,112 @@
+package data
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRealizedVolatility(t *testing.T) {
+	annual := math.Sqrt(minutesperyear) * 100
+	tests := []struct {
+		name   string
+		closes []float64
+		want   float64
+	}{
+		{name: "no returns", closes: []float64{100}, want: 0},
+		{name: "flat", closes: []float64{100, 100, 100}, want: 0},
+		{name: "alternating", closes: []float64{100, 101, 100}, want: math.Log(1.01) * annual},
+		{name: "trend", closes: []float64{100, 110, 121}, want: math.Log(1.1) * annual},
+		// Returns around a missing price are skipped but still divide
+		{name: "skips zero", closes: []float64{100, 0, 100, 110}, want: math.Log(1.1) / math.Sqrt(3) * annual},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RealizedVolatility(tt.closes); math.Abs(got-tt.want) > 1e-9*math.Max(1, tt.want) {
+				t.Errorf("RealizedVolatility(%v) = %v, want %v", tt.closes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVolatilityAdd(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	type price struct {
+		seconds int
+		price   float64
+	}
+	tests := []struct {
+		name   string
+		prices []price
+		want   []float64
+	}{
+		{name: "last price closes the minute", prices: []price{{0, 1}, {30, 2}, {59, 3}}, want: []float64{3}},
+		{name: "next minute", prices: []price{{0, 1}, {60, 2}}, want: []float64{1, 2}},
+		{name: "gap repeats the close", prices: []price{{0, 1}, {180, 2}}, want: []float64{1, 1, 1, 2}},
+		{name: "late trade ignored", prices: []price{{60, 1}, {0, 2}}, want: []float64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v Volatility
+			for _, p := range tt.prices {
+				v.Add(start.Add(time.Duration(p.seconds)*time.Second), p.price)
+			}
+			if !reflect.DeepEqual(v.Closes, tt.want) {
+				t.Errorf("closes %v, want %v", v.Closes, tt.want)
+			}
+		})
+	}
+}
+
+func TestVolatilitySeed(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var v Volatility
+	v.Add(start.Add(3*time.Minute), 4)
+	v.Add(start.Add(4*time.Minute), 5)
+	// Closes of minutes 0 to 4, the last two already kept
+	v.Seed(start, []float64{1, 2, 3, 40, 50})
+	if want := []float64{1, 2, 3, 4, 5}; !reflect.DeepEqual(v.Closes, want) {
+		t.Errorf("closes %v, want %v", v.Closes, want)
+	}
+	// Nothing older to add
+	v.Seed(start.Add(time.Minute), []float64{9, 9})
+	if len(v.Closes) != 5 {
+		t.Errorf("seeded %d closes, want 5", len(v.Closes))
+	}
+}
+
+func TestVolatilityCone(t *testing.T) {
+	v := &Volatility{}
+	// Quiet closes then a volatile last window
+	for i := 0; i < 60; i++ {
+		v.Closes = append(v.Closes, 100+float64(i%2)*0.1)
+	}
+	for i := 0; i < 5; i++ {
+		v.Closes = append(v.Closes, 100+float64(i%2)*5)
+	}
+	windows := []time.Duration{5 * time.Minute, 2 * time.Hour}
+
+	cones := v.Cone(windows)
+	if len(cones) != 1 {
+		t.Fatalf("%d cones, want only the 5m one with enough closes", len(cones))
+	}
+	cone := cones[0]
+	// Windows of 6 closes ending every minute
+	if cone.Samples != 60 {
+		t.Errorf("%d samples, want 60", cone.Samples)
+	}
+	if cone.Current != RealizedVolatility(v.Closes[len(v.Closes)-6:]) {
+		t.Errorf("current %v is not the last window", cone.Current)
+	}
+	if !(cone.Low <= cone.Median && cone.Median <= cone.High && cone.High < cone.Current) {
+		t.Errorf("cone %+v not ordered below the current volatility", cone)
+	}
+
+	if _, regime := v.Regime(windows); regime != 1 {
+		t.Errorf("regime %d, want 1", regime)
+	}
+	if _, regime := v.Regime(windows); regime != 0 {
+		t.Errorf("regime %d reported twice", regime)
+	}
+}
@@ -21,223 +21,123 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"gobit/internal/binance"
 	. "gobit/internal/config"
 	"gobit/internal/data"
-
-	_ "github.com/mattn/go-sqlite3"
-
-	"log"
-	"os"
+	"strings"
 	"time"
 )
 
-// InitDb - Inits the database, optionally creates the file
-func InitDb(path string) ( eventdb *sql.DB, err error) {
-	rotatequery := ""
-	initeventsdbquery := "create table if not exists events(" +
-		"timestamp timestamp," +
-		"eventtype text," +
-		"noticetype text," +
-		"symbol text," +
-		"baseasset text," +
-		"quotaasset text," +
-		"volume float," +
-		"pricechange float," +
-		"period text," +
-		"sendtimestamp timestamp)"
-	inittradesdbquery := "create table if not exists trades(" +
-		"timestamp timestamp," +
-		"eventtype text," +
-		"symbol text," +
-		"quoteasset text," +
-		"baseasset text," +
-		"quantity float," +
-		"price float," +
-		"tradetimestamp timestamp," +
-		"ismaker boolean)"
-	_, err = os.Stat(path)
-	if os.IsNotExist(err) || Conf.Db.InMemory {
-		log.Println("Initializing Database")
-	} else {
-		// rotate database to keep only recent (retention) data
-		log.Println("Rotating Database")
-		rotatequery = "delete from events where " +
-			"datetime(timestamp) < datetime('now','-" +
-			Conf.Db.Retention + "'); delete from trades where " +
-			"datetime(timestamp) < datetime('now','-" +
-			Conf.Db.Retention + "')"
-	}
+// Store - Storage backend of events and trades
+type Store interface {
+	// InsertEvent stores an abnormal event
+	InsertEvent(ev binance.Event) error
+	// InsertTrade stores a large trade
+	InsertTrade(tr binance.Trade, info map[string]data.Symbol) error
+	// InsertCVD stores a cumulative volume delta sample of a symbol
+	InsertCVD(symbol string, cvd float64) error
+	// AssetVolumeFrequency sample period to retention volume ratio
+	AssetVolumeFrequency(baseasset string) float64
+	// Symbols returns the pairs seen during the sample period
+	Symbols() ([]string, error)
+	// SelectEvents returns the stored events matching the query
+	SelectEvents(q Query) ([]data.EventRecord, error)
+	// SelectTrades returns the stored trades matching the query
+	SelectTrades(q Query) ([]data.TradeRecord, error)
+	// SelectOutcomes returns the prices after the notices matching the query
+	SelectOutcomes(q Query) ([]data.Outcome, error)
+	// PendingOutcomes returns the outcomes since a time with unknown prices
+	PendingOutcomes(since time.Time) ([]data.Outcome, error)
+	// UpdateOutcome stores the known prices of an outcome
+	UpdateOutcome(o data.Outcome) error
+	// Close closes the underlying database
+	Close() error
+}
 
-	// Database is stored inside the local cache folder
-	if Conf.Db.InMemory {
-		eventdb, err = sql.Open("sqlite3", "file:gobit.db?cache=shared&mode=memory&_busy_timeout=50000000")
-	} else {
-		eventdb, err = sql.Open("sqlite3", path+"?cache=shared&mode=rwc&_busy_timeout=50000000")
-	}
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, err = eventdb.Exec(inittradesdbquery)
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, err = eventdb.Exec(initeventsdbquery)
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, err = eventdb.Exec(rotatequery)
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, err = eventdb.Exec("vacuum main")
-	if err != nil {
-		log.Fatal(err)
-	}
-	eventdb.SetMaxOpenConns(4)
+// Snapshotter - Store kept in memory that can be saved to disk
+type Snapshotter interface {
+	// Snapshot saves the database to its file
+	Snapshot() error
+}
 
-	return
+// Query - Filters used to select stored events and trades
+type Query struct {
+	From   time.Time
+	To     time.Time
+	Symbol string
+	Base   string
+	Quote  string
+	Notice string
+	Limit  int
+	Offset int
 }
 
-// InsertDbEvent - Gets called when a new event comes
-func InsertDbEvent(ev binance.Event, db *sql.DB) error {
-	st, err := db.Prepare("insert into events(" +
-		"timestamp," +
-		"eventtype," +
-		"noticetype," +
-		"symbol," +
-		"baseasset," +
-		"quotaasset," +
-		"volume," +
-		"pricechange," +
-		"period," +
-		"sendtimestamp" +
-		") values(?,?,?,?,?,?,?,?,?,?)")
-	defer st.Close()
-	if err == nil {
-		sendtimestamp := time.Unix(int64(ev.Data.SendTimestamp)/1000,
-			1000000*(int64(ev.Data.SendTimestamp)%1000))
-		_, err = st.Exec(time.Now(),
-			ev.Data.EventType,
-			ev.Data.NoticeType,
-			ev.Data.Symbol,
-			ev.Data.BaseAsset,
-			ev.Data.QuotaAsset,
-			ev.Data.Volume,
-			ev.Data.PriceChange,
-			ev.Data.Period,
-			sendtimestamp)
+// Supported database drivers
+const (
+	Sqlite   = "sqlite3"
+	Postgres = "postgres"
+)
+
+// InitDb - Inits the configured database backend, path is the sqlite file
+// or the snapshot file of the in-memory database
+func InitDb(path string) (Store, error) {
+	switch Conf.Db.Driver {
+	case Sqlite, "":
+		return initsqlite(path)
+	case Postgres:
+		return initpostgres(Conf.Db.DSN, true)
 	}
-	return err
+	return nil, errors.New("unknown database driver " + Conf.Db.Driver)
 }
 
-// InsertDbTrade - Inserts appropriate trade to db
-func InsertDbTrade(tr binance.Trade, info map[string]data.Symbol, db *sql.DB) error {
-	st, err := db.Prepare("insert into trades(" +
-		"timestamp," +
-		"eventtype," +
-		"symbol," +
-		"quoteasset," +
-		"baseasset," +
-		"quantity," +
-		"price," +
-		"tradetimestamp," +
-		"ismaker" +
-		") values(?,?,?,?,?,?,?,?,?)")
-	defer st.Close()
-	if err == nil {
-		tradetimestamp := time.Unix(int64(tr.Data.TradeTimestamp)/1000,
-			1000*(int64(tr.Data.TradeTimestamp)%1000))
-		_, err = st.Exec(time.Now(),
-			tr.Data.EventType,
-			tr.Data.Symbol,
-			info[tr.Data.Symbol].QuoteAsset,
-			info[tr.Data.Symbol].BaseAsset,
-			tr.Data.Quantity,
-			tr.Data.Price,
-			tradetimestamp,
-			tr.Data.IsMaker)
+// OpenDb - Opens an existing database read only, path is used by sqlite
+func OpenDb(path string) (Store, error) {
+	switch Conf.Db.Driver {
+	case Sqlite, "":
+		return opensqlite(path)
+	case Postgres:
+		return openpostgres(Conf.Db.DSN)
 	}
-	return err
+	return nil, errors.New("unknown database driver " + Conf.Db.Driver)
 }
 
-// AssetVolumeFrequency - Not used yet
-func AssetVolumeFrequency(baseasset string, db *sql.DB) float64 {
-	var volfreq sql.NullFloat64
-	query := "select (select sum(volume) from events where baseasset ==\"" +
-		baseasset + "\" " +
-		"and datetime(timestamp) >= datetime('now','-" +
-		Conf.Db.SamplePeriod + "'))" +
-		"/(select sum(volume) from events where baseasset ==\"" +
-		baseasset + "\" " +
-		"and datetime(timestamp) >= datetime('now','-" +
-		Conf.Db.Retention + "') " +
-		"group by baseasset having count()>10)"
-	err := db.QueryRow(query).Scan(&volfreq)
-	if err == sql.ErrNoRows || volfreq.Valid != true {
-		return 0
-	} else if err != nil {
-		log.Println("Error executing AssetVolumeFrequency query " + err.Error())
-		return 0
-	}
-	return volfreq.Float64
+// dialect - SQL differences between backends
+type dialect interface {
+	// rebind converts ? placeholders to the backend syntax
+	rebind(query string) string
 }
 
-// AssetAvgVolume - Estimate Average Volume
-func AssetAvgVolume(baseasset string, db *sql.DB) float64 {
-	var volavg sql.NullFloat64
-	query := "select avg(volume) from events where baseasset ==\"" +
-		baseasset + "\" " +
-		"and datetime(timestamp) >= datetime('now','-" +
-		Conf.Db.Retention + "')"
-	err := db.QueryRow(query).Scan(&volavg)
-	if err == sql.ErrNoRows || volavg.Valid != true {
-		return 0
-	} else if err != nil {
-		log.Println("Error executing AssetAvgVolume query " + err.Error())
-		return 0
-	}
-	return volavg.Float64
+// sqlstore - Store implementation shared by the sql backends
+type sqlstore struct {
+	db *sql.DB
+	dialect
 }
 
-// AssetMomentum - Estimate and build momentum table
-func AssetMomentum(db *sql.DB) []data.AssetStat {
-	momentumtable := make([]data.AssetStat, 0)
-
-	query := "select h.baseasset, " +
-		"count(t.baseasset)*(sum(distinct(t.volume))/sum(distinct(h.volume))) " +
-		"as momentum from events as h cross join events as t " +
-		"on h.baseasset == t.baseasset where " +
-		"datetime(h.timestamp) >= datetime('now','-" +
-		Conf.Db.Retention + "')" +
-		"and datetime(t.timestamp) >= datetime('now','-" +
-		Conf.Db.SamplePeriod + "')" +
-		"and t.noticetype == 'BLOCK_TRADE' and h.noticetype == 'BLOCK_TRADE' " +
-		"group by h.baseasset having count() > 5 order by momentum DESC limit 7;"
-
-	rows, err := db.Query(query)
-	if err == sql.ErrNoRows {
-		return nil
-
-	} else if err != nil {
-		log.Println("Error executing AssetMomentum query " + err.Error())
-		return nil
-	}
-	for rows.Next() {
-		var name string
-		var momentum sql.NullFloat64
-		err = rows.Scan(&name, &momentum)
-		if err != nil {
-			break
-		}
-		volumeavg := AssetAvgVolume(name, db)
-		momentumtable = append(momentumtable, data.AssetStat{
-			Name: name,
-			Momentum: momentum.Float64,
-			AvgVolume: volumeavg,
-		})
-	}
-	rows.Close()
+// Close - Closes the database
+func (s *sqlstore) Close() error {
+	return s.db.Close()
+}
+
+// derivedmeasures - Moves the measures of derived events stored in volume and
+// pricechange by earlier versions to value and ratio
+var derivedmeasures = "update events set value = volume, ratio = pricechange, volume = 0, pricechange = 0 " +
+	"where noticetype in ('" + strings.Join(data.DerivedNotices, "', '") + "') and (volume <> 0 or pricechange <> 0)"
+
+// derivedoutcomes - Drops the outcomes of indicator crossings, only notices
+// and composite events are tracked
+const derivedoutcomes = "delete from outcomes where noticetype = 'INDICATOR'"
+
+// Times are stored as UTC epoch milliseconds
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func frommillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
 
-	return momentumtable
+// cutoff - Epoch milliseconds of now minus a SQL Syntax period
+func cutoff(period string) int64 {
+	return millis(time.Now().Add(-Interval(period)))
 }
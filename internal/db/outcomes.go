@@ -0,0 +1,118 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package db
+
+import (
+	"gobit/internal/binance"
+	"gobit/internal/data"
+	"strings"
+	"time"
+)
+
+// Price columns of data.OutcomeHorizons
+var outcomecolumns = []string{"price1m", "price5m", "price15m", "price1h"}
+
+// insertoutcome - Starts tracking the prices after a notice with a direction
+func (s *sqlstore) insertoutcome(ev binance.Event) error {
+	direction := data.Direction(ev.Data.EventType)
+	if direction == 0 || ev.Data.Symbol == "" {
+		return nil
+	}
+	// Composite events are scored like the notices, other derived events not
+	if data.DerivedNotice(ev.Data.NoticeType) && ev.Data.NoticeType != "COMPOSITE" {
+		return nil
+	}
+	timestamp := int64(ev.Data.SendTimestamp)
+	if timestamp == 0 {
+		timestamp = millis(time.Now())
+	}
+	_, err := s.db.Exec(s.rebind("insert into outcomes(timestamp, symbol, baseasset, quoteasset, "+
+		"noticetype, eventtype, period, direction, price, "+strings.Join(outcomecolumns, ", ")+
+		") values(?,?,?,?,?,?,?,?,0,0,0,0,0)"),
+		timestamp,
+		ev.Data.Symbol,
+		ev.Data.BaseAsset,
+		ev.Data.QuotaAsset,
+		ev.Data.NoticeType,
+		ev.Data.EventType,
+		ev.Data.Period,
+		direction)
+	return err
+}
+
+// selectoutcomes - Returns the outcomes of a query with a where clause
+func (s *sqlstore) selectoutcomes(where string, args []interface{}) ([]data.Outcome, error) {
+	outcomes := make([]data.Outcome, 0)
+	rows, err := s.db.Query(s.rebind("select timestamp, symbol, baseasset, quoteasset, "+
+		"noticetype, eventtype, period, direction, price, "+strings.Join(outcomecolumns, ", ")+
+		" from outcomes"+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o data.Outcome
+		var timestamp int64
+		o.Prices = make([]float64, len(outcomecolumns))
+		fields := []interface{}{&timestamp, &o.Symbol, &o.BaseAsset, &o.QuoteAsset,
+			&o.NoticeType, &o.EventType, &o.Period, &o.Direction, &o.Price}
+		for i := range o.Prices {
+			fields = append(fields, &o.Prices[i])
+		}
+		if err = rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		o.Timestamp = frommillis(timestamp)
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, rows.Err()
+}
+
+// SelectOutcomes - Returns the prices after the notices matching the query
+func (s *sqlstore) SelectOutcomes(q Query) ([]data.Outcome, error) {
+	where, args := s.querywhere(q, "quoteasset", true)
+	return s.selectoutcomes(where, args)
+}
+
+// PendingOutcomes - Returns the outcomes since a time with unknown prices
+func (s *sqlstore) PendingOutcomes(since time.Time) ([]data.Outcome, error) {
+	return s.selectoutcomes(" where timestamp >= ? and (price = 0 or "+
+		strings.Join(outcomecolumns, " = 0 or ")+" = 0) order by timestamp",
+		[]interface{}{millis(since)})
+}
+
+// UpdateOutcome - Stores the known prices of an outcome, keyed by the time,
+// pair, event type and period of its notice
+func (s *sqlstore) UpdateOutcome(o data.Outcome) error {
+	args := []interface{}{o.Price}
+	for i := range outcomecolumns {
+		price := 0.0
+		if i < len(o.Prices) {
+			price = o.Prices[i]
+		}
+		args = append(args, price)
+	}
+	args = append(args, millis(o.Timestamp), o.Symbol, o.EventType, o.Period)
+	_, err := s.db.Exec(s.rebind("update outcomes set price = ?, "+
+		strings.Join(outcomecolumns, " = ?, ")+" = ? "+
+		"where timestamp = ? and symbol = ? and eventtype = ? and period = ?"), args...)
+	return err
+}
@@ -0,0 +1,209 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	. "gobit/internal/config"
+	"log"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresversion - Schema version written by initpostgres, bumped with every
+// change to its queries that older readers can't handle
+const postgresversion = 2
+
+// postgres - PostgreSQL/TimescaleDB dialect
+type postgres struct{}
+
+// rebind - Converts ? placeholders to numbered $n placeholders
+func (postgres) rebind(query string) string {
+	var b strings.Builder
+	n := 1
+	for _, c := range query {
+		if c == '?' {
+			b.WriteString("$" + strconv.Itoa(n))
+			n++
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// initpostgres - Connects to a PostgreSQL database and creates the tables,
+// optionally rotating old data
+func initpostgres(dsn string, rotate bool) (Store, error) {
+	if dsn == "" {
+		return nil, errors.New("postgres driver requires Db.DSN")
+	}
+	initqueries := []string{
+		"create table if not exists events(" +
+			"timestamp bigint not null," +
+			"eventtype text," +
+			"noticetype text," +
+			"symbol text," +
+			"baseasset text," +
+			"quotaasset text," +
+			"volume double precision," +
+			"pricechange double precision," +
+			"period text," +
+			"sendtimestamp bigint," +
+			"details text," +
+			"value double precision," +
+			"ratio double precision)",
+		"create table if not exists trades(" +
+			"timestamp bigint not null," +
+			"eventtype text," +
+			"symbol text," +
+			"quoteasset text," +
+			"baseasset text," +
+			"quantity double precision," +
+			"price double precision," +
+			"tradetimestamp bigint," +
+			"ismaker boolean)",
+		"create table if not exists cvd(" +
+			"timestamp bigint not null," +
+			"symbol text," +
+			"cvd double precision)",
+		"create table if not exists outcomes(" +
+			"timestamp bigint not null," +
+			"symbol text," +
+			"baseasset text," +
+			"quoteasset text," +
+			"noticetype text," +
+			"eventtype text," +
+			"direction integer," +
+			"price double precision," +
+			"price1m double precision," +
+			"price5m double precision," +
+			"price15m double precision," +
+			"price1h double precision," +
+			"period text not null default '')",
+		"alter table events add column if not exists details text",
+		"alter table events add column if not exists value double precision",
+		"alter table events add column if not exists ratio double precision",
+		"alter table outcomes add column if not exists period text not null default ''",
+		derivedmeasures,
+		derivedoutcomes,
+		"create index if not exists events_baseasset_timestamp on events(baseasset, timestamp)",
+		"create index if not exists trades_symbol_timestamp on trades(symbol, timestamp)",
+		"create index if not exists outcomes_timestamp on outcomes(timestamp)",
+		"create table if not exists schemaversion(version integer not null)",
+	}
+	// Earlier timestamptz columns are converted to epoch milliseconds
+	for _, column := range [][2]string{{"events", "timestamp"}, {"events", "sendtimestamp"},
+		{"trades", "timestamp"}, {"trades", "tradetimestamp"}} {
+		initqueries = append(initqueries, "do $$ begin "+
+			"if exists (select 1 from information_schema.columns where table_schema = current_schema() "+
+			"and table_name = '"+column[0]+"' "+
+			"and column_name = '"+column[1]+"' and data_type like 'timestamp%') then "+
+			"alter table "+column[0]+" alter column "+column[1]+" type bigint "+
+			"using (extract(epoch from "+column[1]+")*1000)::bigint; "+
+			"end if; end $$")
+	}
+	if Conf.Db.Timescale {
+		// Hypertables partition both tables by receive time in daily chunks
+		initqueries = append(initqueries,
+			"create extension if not exists timescaledb",
+			"select create_hypertable('events', 'timestamp', chunk_time_interval => 86400000, "+
+				"if_not_exists => true, migrate_data => true)",
+			"select create_hypertable('trades', 'timestamp', chunk_time_interval => 86400000, "+
+				"if_not_exists => true, migrate_data => true)",
+			"select create_hypertable('cvd', 'timestamp', chunk_time_interval => 86400000, "+
+				"if_not_exists => true, migrate_data => true)")
+	}
+	initqueries = append(initqueries, "delete from schemaversion",
+		"insert into schemaversion values("+strconv.Itoa(postgresversion)+")")
+
+	eventdb, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = eventdb.Ping(); err != nil {
+		eventdb.Close()
+		return nil, err
+	}
+	for _, query := range initqueries {
+		if _, err = eventdb.Exec(query); err != nil {
+			eventdb.Close()
+			return nil, err
+		}
+	}
+
+	// rotate database to keep only recent (retention) data
+	for _, table := range []string{"events", "trades", "cvd", "outcomes"} {
+		if !rotate {
+			break
+		}
+		retention := Conf.Db.Retention
+		if table == "outcomes" {
+			retention = Conf.Db.OutcomeRetention
+		}
+		log.Println("Rotating Database " + table)
+		_, err = eventdb.Exec("delete from "+table+" where timestamp < $1", cutoff(retention))
+		if err != nil {
+			eventdb.Close()
+			return nil, err
+		}
+	}
+	eventdb.SetMaxOpenConns(4)
+
+	return &sqlstore{db: eventdb, dialect: postgres{}}, nil
+}
+
+// openpostgres - Connects to a PostgreSQL database created by initpostgres,
+// failing instead of migrating when its schema is out of date
+func openpostgres(dsn string) (Store, error) {
+	if dsn == "" {
+		return nil, errors.New("postgres driver requires Db.DSN")
+	}
+	eventdb, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = eventdb.Ping(); err != nil {
+		eventdb.Close()
+		return nil, err
+	}
+	// Databases of earlier versions have no schemaversion table
+	var versioned bool
+	version := 0
+	err = eventdb.QueryRow("select to_regclass('schemaversion') is not null").Scan(&versioned)
+	if err == nil && versioned {
+		err = eventdb.QueryRow("select coalesce(max(version), 0) from schemaversion").Scan(&version)
+	}
+	if err != nil {
+		eventdb.Close()
+		return nil, err
+	}
+	if version < postgresversion {
+		eventdb.Close()
+		return nil, errors.New("postgres schema version " + strconv.Itoa(version) +
+			" is out of date, run gobit once to migrate it")
+	}
+	eventdb.SetMaxOpenConns(4)
+
+	return &sqlstore{db: eventdb, dialect: postgres{}}, nil
+}
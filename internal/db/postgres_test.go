@@ -0,0 +1,239 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package db
+
+import (
+	"database/sql"
+	"gobit/internal/binance"
+	"gobit/internal/data"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postgrestest - Connects to the database of GOBIT_TEST_DSN in a new schema,
+// dropped after the test, skipping the test without it
+func postgrestest(t *testing.T) (admin *sql.DB, dsn, schema string) {
+	t.Helper()
+	dsn = os.Getenv("GOBIT_TEST_DSN")
+	if dsn == "" {
+		t.Skip("GOBIT_TEST_DSN not set")
+	}
+	var err error
+	admin, err = sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema = "gobit_test_" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	if _, err = admin.Exec("create schema " + schema); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("drop schema " + schema + " cascade")
+		admin.Close()
+	})
+
+	// Unknown connection parameters are sent as run-time settings
+	switch {
+	case !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://"):
+		dsn += " search_path=" + schema
+	case strings.Contains(dsn, "?"):
+		dsn += "&search_path=" + schema
+	default:
+		dsn += "?search_path=" + schema
+	}
+	return admin, dsn, schema
+}
+
+func TestPostgresStore(t *testing.T) {
+	admin, dsn, schema := postgrestest(t)
+
+	// Tables of the timestamptz schema with a row each
+	legacy, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer legacy.Close()
+	for _, query := range []string{
+		"create table events(timestamp timestamptz not null, eventtype text, noticetype text, " +
+			"symbol text, baseasset text, quotaasset text, volume double precision, " +
+			"pricechange double precision, period text, sendtimestamp timestamptz)",
+		"create table trades(timestamp timestamptz not null, eventtype text, symbol text, " +
+			"quoteasset text, baseasset text, quantity double precision, price double precision, " +
+			"tradetimestamp timestamptz, ismaker boolean)",
+		"insert into events values('2023-11-14 22:13:20.123+00', 'UP_1', 'PRICE_CHANGE', " +
+			"'OLDUSDT', 'OLD', 'USDT', 1.5, 0.02, 'MINUTE_5', '2023-11-14 22:13:20.045+00')",
+		"insert into trades values('2023-11-14 22:13:20.123+00', 'aggTrade', 'OLDUSDT', " +
+			"'USDT', 'OLD', 2, 3.5, '2023-11-14 22:13:20.001+00', true)",
+	} {
+		if _, err = legacy.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reading doesn't migrate the earlier schema
+	if _, err = openpostgres(dsn); err == nil {
+		t.Fatal("opened the timestamptz schema")
+	}
+
+	store, err := initpostgres(dsn, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// timestamptz columns are epoch milliseconds now
+	var columns int
+	err = admin.QueryRow("select count(*) from information_schema.columns where table_schema = $1 "+
+		"and column_name in ('timestamp', 'sendtimestamp', 'tradetimestamp') and data_type = 'bigint'",
+		schema).Scan(&columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns != 4 {
+		t.Errorf("%d bigint time columns, want 4", columns)
+	}
+	events, err := store.SelectEvents(Query{Symbol: "OLDUSDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || !events[0].Timestamp.Equal(frommillis(1700000000123)) ||
+		!events[0].SendTimestamp.Equal(frommillis(1700000000045)) || events[0].Details != "" {
+		t.Errorf("converted events = %+v", events)
+	}
+	trades, err := store.SelectTrades(Query{Symbol: "OLDUSDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || !trades[0].Timestamp.Equal(frommillis(1700000000123)) ||
+		!trades[0].TradeTimestamp.Equal(frommillis(1700000000001)) {
+		t.Errorf("converted trades = %+v", trades)
+	}
+
+	// Inserted rows read back in time order
+	now := uint64(millis(time.Now()))
+	for i, symbol := range []string{"BTCUSDT", "ETHBTC"} {
+		var ev binance.Event
+		ev.ReceiveTimestamp = now + uint64(i)
+		ev.Data.NoticeType = "PRICE_CHANGE"
+		ev.Data.EventType = "UP_2"
+		ev.Data.Symbol = symbol
+		ev.Data.BaseAsset = strings.TrimSuffix(strings.TrimSuffix(symbol, "USDT"), "BTC")
+		ev.Data.QuotaAsset = strings.TrimPrefix(symbol, ev.Data.BaseAsset)
+		ev.Data.Period = "MINUTE_5"
+		ev.Data.SendTimestamp = now - 10
+		ev.Data.Details = "test"
+		if err = store.InsertEvent(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var tr binance.Trade
+	tr.ReceiveTimestamp = now
+	tr.Data.EventType = "aggTrade"
+	tr.Data.Symbol = "BTCUSDT"
+	tr.Data.Quantity = 1.25
+	tr.Data.Price = 35000
+	tr.Data.TradeTimestamp = now - 5
+	tr.Data.IsMaker = true
+	info := map[string]data.Symbol{"BTCUSDT": {Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT"}}
+	if err = store.InsertTrade(tr, info); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		q      Query
+		events []string
+		trades []string
+	}{
+		{"all", Query{}, []string{"OLDUSDT", "BTCUSDT", "ETHBTC"}, []string{"OLDUSDT", "BTCUSDT"}},
+		{"from", Query{From: frommillis(int64(now))}, []string{"BTCUSDT", "ETHBTC"}, []string{"BTCUSDT"}},
+		{"to", Query{To: frommillis(int64(now) - 1)}, []string{"OLDUSDT"}, []string{"OLDUSDT"}},
+		{"symbol", Query{Symbol: "btc/usdt"}, []string{"BTCUSDT"}, []string{"BTCUSDT"}},
+		{"quote", Query{Quote: "BTC"}, []string{"ETHBTC"}, []string{}},
+		{"notice", Query{Notice: "PRICE_CHANGE", From: frommillis(int64(now))}, []string{"BTCUSDT", "ETHBTC"}, []string{}},
+		{"limit", Query{Limit: 1, Offset: 1}, []string{"BTCUSDT"}, []string{"BTCUSDT"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, err := store.SelectEvents(test.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0)
+			for _, ev := range events {
+				got = append(got, ev.Symbol)
+			}
+			if strings.Join(got, ",") != strings.Join(test.events, ",") {
+				t.Errorf("events %v, want %v", got, test.events)
+			}
+			trades, err := store.SelectTrades(test.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = make([]string, 0)
+			for _, tr := range trades {
+				got = append(got, tr.Symbol)
+			}
+			if strings.Join(got, ",") != strings.Join(test.trades, ",") {
+				t.Errorf("trades %v, want %v", got, test.trades)
+			}
+		})
+	}
+
+	trades, err = store.SelectTrades(Query{Symbol: "BTCUSDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].BaseAsset != "BTC" || trades[0].QuoteAsset != "USDT" ||
+		!trades[0].TradeTimestamp.Equal(frommillis(int64(now)-5)) || !trades[0].IsMaker {
+		t.Errorf("inserted trades = %+v", trades)
+	}
+
+	// Pairs of the sample period only
+	symbols, err := store.Symbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(symbols)
+	if strings.Join(symbols, ",") != "BTCUSDT,ETHBTC" {
+		t.Errorf("symbols %v, want BTCUSDT,ETHBTC", symbols)
+	}
+
+	// Initializing again keeps the converted tables
+	again, err := initpostgres(dsn, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again.Close()
+
+	reader, err := openpostgres(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if events, err = reader.SelectEvents(Query{}); err != nil || len(events) != 3 {
+		t.Errorf("read %d events, %v", len(events), err)
+	}
+}
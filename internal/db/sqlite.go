@@ -0,0 +1,404 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	. "gobit/internal/config"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// memorystore - In-memory sqlite store with snapshots to the database file
+type memorystore struct {
+	sqlstore
+	path string
+}
+
+// sqlite - SQLite dialect
+type sqlite struct{}
+
+func (sqlite) rebind(query string) string {
+	return query
+}
+
+// Events table of schema version 1
+const sqliteeventsv1 = "create table if not exists events(" +
+	"timestamp integer," +
+	"eventtype text," +
+	"noticetype text," +
+	"symbol text," +
+	"baseasset text," +
+	"quotaasset text," +
+	"volume float," +
+	"pricechange float," +
+	"period text," +
+	"sendtimestamp integer)"
+
+// Latest sqlite schema, times are UTC epoch milliseconds
+var sqliteschema = []string{
+	"create table if not exists events(" +
+		"timestamp integer," +
+		"eventtype text," +
+		"noticetype text," +
+		"symbol text," +
+		"baseasset text," +
+		"quotaasset text," +
+		"volume float," +
+		"pricechange float," +
+		"period text," +
+		"sendtimestamp integer," +
+		"details text," +
+		"value float," +
+		"ratio float)",
+	"create table if not exists trades(" +
+		"timestamp integer," +
+		"eventtype text," +
+		"symbol text," +
+		"quoteasset text," +
+		"baseasset text," +
+		"quantity float," +
+		"price float," +
+		"tradetimestamp integer," +
+		"ismaker boolean)",
+	"create table if not exists cvd(" +
+		"timestamp integer," +
+		"symbol text," +
+		"cvd float)",
+	"create table if not exists outcomes(" +
+		"timestamp integer," +
+		"symbol text," +
+		"baseasset text," +
+		"quoteasset text," +
+		"noticetype text," +
+		"eventtype text," +
+		"direction integer," +
+		"price float," +
+		"price1m float," +
+		"price5m float," +
+		"price15m float," +
+		"price1h float," +
+		"period text not null default '')",
+}
+
+// Trades table of schema version 1
+const sqlitetradesv1 = "create table if not exists trades(" +
+	"timestamp integer," +
+	"eventtype text," +
+	"symbol text," +
+	"quoteasset text," +
+	"baseasset text," +
+	"quantity float," +
+	"price float," +
+	"tradetimestamp integer," +
+	"ismaker boolean)"
+
+// Cvd table of schema version 2
+const sqlitecvdv2 = "create table if not exists cvd(" +
+	"timestamp integer," +
+	"symbol text," +
+	"cvd float)"
+
+// Outcomes table of schema version 4
+const sqliteoutcomesv4 = "create table if not exists outcomes(" +
+	"timestamp integer," +
+	"symbol text," +
+	"baseasset text," +
+	"quoteasset text," +
+	"noticetype text," +
+	"eventtype text," +
+	"direction integer," +
+	"price float," +
+	"price1m float," +
+	"price5m float," +
+	"price15m float," +
+	"price1h float)"
+
+// sqlitemigration - Upgrade step run inside its own transaction
+type sqlitemigration func(tx *sql.Tx) error
+
+// sqlitestatements - Upgrade step executing queries
+func sqlitestatements(queries ...string) sqlitemigration {
+	return func(tx *sql.Tx) error {
+		for _, query := range queries {
+			if _, err := tx.Exec(query); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// sqlitemigrations - Upgrade steps of existing databases, the schema version
+// kept in user_version is the number of applied steps
+var sqlitemigrations = []sqlitemigration{
+	// 1: timestamps from driver formatted text to epoch milliseconds
+	migratesqlitev1,
+	// 2: cumulative volume delta samples
+	sqlitestatements(sqlitecvdv2),
+	// 3: explanation of composite events
+	sqlitestatements("alter table events add column details text"),
+	// 4: prices after notices
+	sqlitestatements(sqliteoutcomesv4),
+	// 5: measures of derived events out of volume and pricechange
+	sqlitestatements(
+		"alter table events add column value float",
+		"alter table events add column ratio float",
+		derivedmeasures,
+		derivedoutcomes),
+	// 6: period of the outcome notices, same time notices of other periods
+	// are told apart
+	sqlitestatements("alter table outcomes add column period text not null default ''"),
+}
+
+// migratesqlitev1 - Converts the driver formatted times of version 0 to epoch
+// milliseconds. Version 0 stored the trade times with the milliseconds as
+// microseconds, they are scaled back while copying.
+func migratesqlitev1(tx *sql.Tx) error {
+	err := sqlitestatements(
+		"alter table events rename to events_v0",
+		"alter table trades rename to trades_v0",
+		sqliteeventsv1,
+		sqlitetradesv1,
+		"insert into events select "+
+			"cast(round((julianday(timestamp)-2440587.5)*86400000) as integer),"+
+			"eventtype,noticetype,symbol,baseasset,quotaasset,volume,pricechange,period,"+
+			"cast(round((julianday(sendtimestamp)-2440587.5)*86400000) as integer) from events_v0",
+	)(tx)
+	if err != nil {
+		return err
+	}
+
+	// The driver parses the timestamp columns with their nanoseconds
+	rows, err := tx.Query("select timestamp, eventtype, symbol, quoteasset, baseasset, " +
+		"quantity, price, tradetimestamp, ismaker from trades_v0")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	st, err := tx.Prepare("insert into trades values(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	for rows.Next() {
+		var timestamp, tradetimestamp sql.NullTime
+		var eventtype, symbol, quoteasset, baseasset sql.NullString
+		var quantity, price sql.NullFloat64
+		var ismaker sql.NullBool
+		err = rows.Scan(&timestamp, &eventtype, &symbol, &quoteasset, &baseasset,
+			&quantity, &price, &tradetimestamp, &ismaker)
+		if err != nil {
+			return err
+		}
+		var ms, tradems sql.NullInt64
+		if timestamp.Valid {
+			ms = sql.NullInt64{Int64: millis(timestamp.Time), Valid: true}
+		}
+		if tradetimestamp.Valid {
+			t := tradetimestamp.Time
+			tradems = sql.NullInt64{Int64: t.Unix()*1000 + int64(t.Nanosecond()/1000), Valid: true}
+		}
+		_, err = st.Exec(ms, eventtype, symbol, quoteasset, baseasset, quantity, price, tradems, ismaker)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return sqlitestatements("drop table events_v0", "drop table trades_v0")(tx)
+}
+
+// migratesqlite - Creates the latest schema or upgrades an existing database,
+// every step commits with its schema version so a failed step runs alone again
+func migratesqlite(eventdb *sql.DB) error {
+	var version, tables int
+	err := eventdb.QueryRow("pragma user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+	err = eventdb.QueryRow("select count(*) from sqlite_master " +
+		"where type = 'table' and name = 'events'").Scan(&tables)
+	if err != nil {
+		return err
+	}
+
+	if tables == 0 {
+		return sqlitestep(eventdb, sqlitestatements(sqliteschema...), len(sqlitemigrations))
+	}
+	for ; version < len(sqlitemigrations); version++ {
+		log.Printf("Migrating Database to version %d\n", version+1)
+		if err = sqlitestep(eventdb, sqlitemigrations[version], version+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlitestep - Runs a step and sets the schema version in one transaction
+func sqlitestep(eventdb *sql.DB, step sqlitemigration, version int) error {
+	tx, err := eventdb.Begin()
+	if err != nil {
+		return err
+	}
+	if err = step(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec("pragma user_version = " + strconv.Itoa(version)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// initsqlite - Inits the sqlite database, optionally creates the file
+func initsqlite(path string) (Store, error) {
+	var eventdb *sql.DB
+	rotate := false
+	_, err := os.Stat(path)
+	exists := !os.IsNotExist(err)
+
+	// Database is stored inside the local cache folder
+	if Conf.Db.InMemory {
+		eventdb, err = sql.Open("sqlite3", "file:gobit.db?cache=shared&mode=memory&_busy_timeout=50000000")
+		if err == nil && exists {
+			// Load the last snapshot back into memory
+			log.Println("Restoring Database snapshot")
+			if err = restore(eventdb, path); err != nil {
+				log.Println("Error restoring database snapshot " + err.Error())
+				exists = false
+			}
+		}
+	} else {
+		eventdb, err = sql.Open("sqlite3", path+"?cache=shared&mode=rwc&_busy_timeout=50000000")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !exists {
+		log.Println("Initializing Database")
+	} else {
+		// rotate database to keep only recent (retention) data
+		log.Println("Rotating Database")
+		rotate = true
+	}
+	err = migratesqlite(eventdb)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if rotate {
+		_, err = eventdb.Exec("delete from events where timestamp < ?; "+
+			"delete from trades where timestamp < ?; "+
+			"delete from cvd where timestamp < ?; "+
+			"delete from outcomes where timestamp < ?",
+			cutoff(Conf.Db.Retention), cutoff(Conf.Db.Retention), cutoff(Conf.Db.Retention),
+			cutoff(Conf.Db.OutcomeRetention))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	_, err = eventdb.Exec("vacuum main")
+	if err != nil {
+		log.Fatal(err)
+	}
+	eventdb.SetMaxOpenConns(4)
+
+	if Conf.Db.InMemory {
+		return &memorystore{sqlstore{db: eventdb, dialect: sqlite{}}, path}, nil
+	}
+	return &sqlstore{db: eventdb, dialect: sqlite{}}, nil
+}
+
+// opensqlite - Opens an existing database file read only
+func opensqlite(path string) (Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	eventdb, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_busy_timeout=50000000")
+	if err != nil {
+		return nil, err
+	}
+	return &sqlstore{db: eventdb, dialect: sqlite{}}, nil
+}
+
+// Snapshot - Saves the in-memory database to the database file
+func (s *memorystore) Snapshot() error {
+	tmp := s.path + ".tmp"
+	os.Remove(tmp)
+	file, err := sql.Open("sqlite3", tmp+"?mode=rwc")
+	if err != nil {
+		return err
+	}
+	err = backup(file, s.db)
+	file.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// Replace the previous snapshot only once the backup is complete
+	return os.Rename(tmp, s.path)
+}
+
+// restore - Loads a database file into the in-memory database
+func restore(memory *sql.DB, path string) error {
+	file, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return backup(memory, file)
+}
+
+// backup - Copies src to dst using the sqlite online backup API
+func backup(dst, src *sql.DB) error {
+	ctx := context.Background()
+	dstconn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstconn.Close()
+	srcconn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcconn.Close()
+
+	return dstconn.Raw(func(dstdriver interface{}) error {
+		return srcconn.Raw(func(srcdriver interface{}) error {
+			b, err := dstdriver.(*sqlite3.SQLiteConn).Backup("main", srcdriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			// Copy all pages in a single step
+			if _, err = b.Step(-1); err != nil {
+				b.Finish()
+				return err
+			}
+			return b.Finish()
+		})
+	})
+}
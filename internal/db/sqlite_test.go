@@ -0,0 +1,280 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package db
+
+import (
+	"database/sql"
+	"gobit/internal/binance"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Version 0 schema, times stored by the driver as formatted text
+const sqlitev0 = "create table events(" +
+	"timestamp timestamp," +
+	"eventtype text," +
+	"noticetype text," +
+	"symbol text," +
+	"baseasset text," +
+	"quotaasset text," +
+	"volume float," +
+	"pricechange float," +
+	"period text," +
+	"sendtimestamp timestamp);" +
+	"create table trades(" +
+	"timestamp timestamp," +
+	"eventtype text," +
+	"symbol text," +
+	"quoteasset text," +
+	"baseasset text," +
+	"quantity float," +
+	"price float," +
+	"tradetimestamp timestamp," +
+	"ismaker boolean)"
+
+// sqlitefixture - Version 0 database with one event and trades stored the
+// way version 0 did, trade milliseconds as microseconds
+func sqlitefixture(t *testing.T, tradems []int64) *sql.DB {
+	t.Helper()
+	eventdb, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "v0.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { eventdb.Close() })
+	if _, err = eventdb.Exec(sqlitev0); err != nil {
+		t.Fatal(err)
+	}
+	received := frommillis(1700000000123)
+	_, err = eventdb.Exec("insert into events values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		received, "UP_1", "PRICE_CHANGE", "BTCUSDT", "BTC", "USDT", 1.5, 0.02, "MINUTE_5",
+		time.Unix(1700000000, 45*int64(time.Millisecond)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ms := range tradems {
+		_, err = eventdb.Exec("insert into trades values(?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			received, "aggTrade", "BTCUSDT", "USDT", "BTC", 2.0, 35000.0,
+			time.Unix(ms/1000, 1000*(ms%1000)), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return eventdb
+}
+
+func TestMigrateSqlite(t *testing.T) {
+	tests := []struct {
+		name    string
+		tradems []int64
+	}{
+		{"no trades", nil},
+		{"whole seconds", []int64{1700000000000}},
+		{"trailing zeros", []int64{1700000000120, 1700000000100}},
+		{"milliseconds", []int64{1700000000001, 1700000000999}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eventdb := sqlitefixture(t, test.tradems)
+			if err := migratesqlite(eventdb); err != nil {
+				t.Fatal(err)
+			}
+			var version int
+			if err := eventdb.QueryRow("pragma user_version").Scan(&version); err != nil {
+				t.Fatal(err)
+			}
+			if version != len(sqlitemigrations) {
+				t.Errorf("user_version = %d, want %d", version, len(sqlitemigrations))
+			}
+
+			var timestamp, sendtimestamp int64
+			var details sql.NullString
+			err := eventdb.QueryRow("select timestamp, sendtimestamp, details from events").
+				Scan(&timestamp, &sendtimestamp, &details)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if timestamp != 1700000000123 || sendtimestamp != 1700000000045 || details.Valid {
+				t.Errorf("event = %d, %d, %v", timestamp, sendtimestamp, details)
+			}
+
+			rows, err := eventdb.Query("select timestamp, tradetimestamp from trades order by rowid")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			i := 0
+			for ; rows.Next(); i++ {
+				if err = rows.Scan(&timestamp, &sendtimestamp); err != nil {
+					t.Fatal(err)
+				}
+				if timestamp != 1700000000123 || sendtimestamp != test.tradems[i] {
+					t.Errorf("trade %d = %d, %d, want tradetimestamp %d", i, timestamp, sendtimestamp, test.tradems[i])
+				}
+			}
+			if i != len(test.tradems) {
+				t.Errorf("%d trades, want %d", i, len(test.tradems))
+			}
+
+			// Migrated databases stay at the last version
+			if err = migratesqlite(eventdb); err != nil {
+				t.Fatal(err)
+			}
+			for _, table := range []string{"cvd", "outcomes", "events_v0", "trades_v0"} {
+				var n int
+				eventdb.QueryRow("select count(*) from sqlite_master where name = ?", table).Scan(&n)
+				if want := table == "cvd" || table == "outcomes"; (n == 1) != want {
+					t.Errorf("table %s exists %v, want %v", table, n == 1, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateSqliteDerived(t *testing.T) {
+	eventdb := sqlitefixture(t, nil)
+	_, err := eventdb.Exec("insert into events values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		time.Now(), "PUMP_SUSPECTED", "COMPOSITE", "BTCUSDT", "BTC", "USDT", 7.0, 0.0, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = migratesqlite(eventdb); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		notice                            string
+		volume, pricechange, value, ratio float64
+	}{
+		{"PRICE_CHANGE", 1.5, 0.02, 0, 0},
+		{"COMPOSITE", 0, 0, 7, 0},
+	}
+	for _, test := range tests {
+		var volume, pricechange, value, ratio float64
+		err = eventdb.QueryRow("select volume, pricechange, coalesce(value, 0), coalesce(ratio, 0) "+
+			"from events where noticetype = ?", test.notice).Scan(&volume, &pricechange, &value, &ratio)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if volume != test.volume || pricechange != test.pricechange || value != test.value || ratio != test.ratio {
+			t.Errorf("%s = %v, %v, %v, %v, want %v, %v, %v, %v", test.notice, volume, pricechange,
+				value, ratio, test.volume, test.pricechange, test.value, test.ratio)
+		}
+	}
+}
+
+func TestMigrateSqliteRollback(t *testing.T) {
+	eventdb := sqlitefixture(t, []int64{1700000000001})
+	// Step 1 fails on the rename and leaves version 0 as it was
+	if _, err := eventdb.Exec("create table trades_v0(x integer)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migratesqlite(eventdb); err == nil {
+		t.Fatal("migration of a conflicting database succeeded")
+	}
+	var version, events int
+	eventdb.QueryRow("pragma user_version").Scan(&version)
+	eventdb.QueryRow("select count(*) from events").Scan(&events)
+	if version != 0 || events != 1 {
+		t.Errorf("user_version = %d with %d events, want 0 with 1", version, events)
+	}
+
+	// Removing the conflict lets the same steps run again
+	if _, err := eventdb.Exec("drop table trades_v0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migratesqlite(eventdb); err != nil {
+		t.Fatal(err)
+	}
+	eventdb.QueryRow("pragma user_version").Scan(&version)
+	if version != len(sqlitemigrations) {
+		t.Errorf("user_version = %d, want %d", version, len(sqlitemigrations))
+	}
+}
+
+func TestCreateSqlite(t *testing.T) {
+	eventdb, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "new.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eventdb.Close()
+	if err = migratesqlite(eventdb); err != nil {
+		t.Fatal(err)
+	}
+	var version int
+	eventdb.QueryRow("pragma user_version").Scan(&version)
+	if version != len(sqlitemigrations) {
+		t.Errorf("user_version = %d, want %d", version, len(sqlitemigrations))
+	}
+	for _, table := range []string{"events", "trades", "cvd", "outcomes"} {
+		if _, err = eventdb.Exec("select * from " + table); err != nil {
+			t.Errorf("table %s: %v", table, err)
+		}
+	}
+}
+
+func TestUpdateOutcome(t *testing.T) {
+	eventdb, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "outcomes.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eventdb.Close()
+	if err = migratesqlite(eventdb); err != nil {
+		t.Fatal(err)
+	}
+	store := &sqlstore{db: eventdb, dialect: sqlite{}}
+
+	// Notices of two periods sent in the same millisecond
+	now := millis(time.Now())
+	for _, period := range []string{"MINUTE_5", "HOUR_2"} {
+		var ev binance.Event
+		ev.Data.NoticeType = "PRICE_CHANGE"
+		ev.Data.EventType = "UP_2"
+		ev.Data.Symbol = "BTCUSDT"
+		ev.Data.Period = period
+		ev.Data.SendTimestamp = uint64(now)
+		if err = store.insertoutcome(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pending, err := store.PendingOutcomes(frommillis(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("%d pending outcomes, want 2", len(pending))
+	}
+	for i := range pending {
+		pending[i].Price = float64(i + 1)
+		if err = store.UpdateOutcome(pending[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outcomes, err := store.SelectOutcomes(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prices := make(map[string]float64)
+	for _, o := range outcomes {
+		prices[o.Period] = o.Price
+	}
+	if prices[pending[0].Period] != 1 || prices[pending[1].Period] != 2 {
+		t.Errorf("prices %v, want 1 and 2 by period", prices)
+	}
+}
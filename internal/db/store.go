@@ -0,0 +1,276 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package db
+
+import (
+	"database/sql"
+	"gobit/internal/binance"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"log"
+	"strings"
+	"time"
+)
+
+// InsertEvent - Gets called when a new event comes, notices with a
+// direction also start tracking their outcome
+func (s *sqlstore) InsertEvent(ev binance.Event) error {
+	st, err := s.db.Prepare(s.rebind("insert into events(" +
+		"timestamp," +
+		"eventtype," +
+		"noticetype," +
+		"symbol," +
+		"baseasset," +
+		"quotaasset," +
+		"volume," +
+		"pricechange," +
+		"period," +
+		"sendtimestamp," +
+		"details," +
+		"value," +
+		"ratio" +
+		") values(?,?,?,?,?,?,?,?,?,?,?,?,?)"))
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	receivetimestamp := int64(ev.ReceiveTimestamp)
+	if receivetimestamp == 0 {
+		receivetimestamp = millis(time.Now())
+	}
+	_, err = st.Exec(receivetimestamp,
+		ev.Data.EventType,
+		ev.Data.NoticeType,
+		ev.Data.Symbol,
+		ev.Data.BaseAsset,
+		ev.Data.QuotaAsset,
+		ev.Data.Volume,
+		ev.Data.PriceChange,
+		ev.Data.Period,
+		int64(ev.Data.SendTimestamp),
+		ev.Data.Details,
+		ev.Data.Value,
+		ev.Data.Ratio)
+	if err != nil {
+		return err
+	}
+	return s.insertoutcome(ev)
+}
+
+// InsertTrade - Inserts appropriate trade to db
+func (s *sqlstore) InsertTrade(tr binance.Trade, info map[string]data.Symbol) error {
+	st, err := s.db.Prepare(s.rebind("insert into trades(" +
+		"timestamp," +
+		"eventtype," +
+		"symbol," +
+		"quoteasset," +
+		"baseasset," +
+		"quantity," +
+		"price," +
+		"tradetimestamp," +
+		"ismaker" +
+		") values(?,?,?,?,?,?,?,?,?)"))
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	receivetimestamp := int64(tr.ReceiveTimestamp)
+	if receivetimestamp == 0 {
+		receivetimestamp = millis(time.Now())
+	}
+	_, err = st.Exec(receivetimestamp,
+		tr.Data.EventType,
+		tr.Data.Symbol,
+		info[tr.Data.Symbol].QuoteAsset,
+		info[tr.Data.Symbol].BaseAsset,
+		tr.Data.Quantity,
+		tr.Data.Price,
+		int64(tr.Data.TradeTimestamp),
+		tr.Data.IsMaker)
+	return err
+}
+
+// InsertCVD - Stores a cumulative volume delta sample
+func (s *sqlstore) InsertCVD(symbol string, cvd float64) error {
+	_, err := s.db.Exec(s.rebind("insert into cvd(timestamp, symbol, cvd) values(?,?,?)"),
+		millis(time.Now()), symbol, cvd)
+	return err
+}
+
+// AssetVolumeFrequency - Not used yet
+func (s *sqlstore) AssetVolumeFrequency(baseasset string) float64 {
+	var volfreq sql.NullFloat64
+	query := "select (select sum(volume) from events where baseasset = ? " +
+		"and timestamp >= ?)" +
+		"/(select sum(volume) from events where baseasset = ? " +
+		"and timestamp >= ? " +
+		"group by baseasset having count(*)>10)"
+	err := s.db.QueryRow(s.rebind(query),
+		baseasset, cutoff(Conf.Db.SamplePeriod),
+		baseasset, cutoff(Conf.Db.Retention)).Scan(&volfreq)
+	if err == sql.ErrNoRows || volfreq.Valid != true {
+		return 0
+	} else if err != nil {
+		log.Println("Error executing AssetVolumeFrequency query " + err.Error())
+		return 0
+	}
+	return volfreq.Float64
+}
+
+// Symbols - Distinct pairs of events and trades during the sample period
+func (s *sqlstore) Symbols() ([]string, error) {
+	var pairs []string
+	query := "select distinct(symbol) from " +
+		"(select symbol,timestamp from events union " +
+		"select symbol,timestamp from trades) as s " +
+		"where timestamp >= ?"
+	rows, err := s.db.Query(s.rebind(query), cutoff(Conf.Db.SamplePeriod))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pair string
+		if err = rows.Scan(&pair); err != nil {
+			return pairs, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
+// querywhere - Builds the where clause of a Query for the given asset columns
+func (s *sqlstore) querywhere(q Query, quotecolumn string, events bool) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	if !q.From.IsZero() {
+		clauses = append(clauses, "timestamp >= ?")
+		args = append(args, millis(q.From))
+	}
+	if !q.To.IsZero() {
+		clauses = append(clauses, "timestamp <= ?")
+		args = append(args, millis(q.To))
+	}
+	if q.Symbol != "" {
+		clauses = append(clauses, "symbol = ?")
+		args = append(args, strings.ToUpper(strings.Replace(q.Symbol, "/", "", -1)))
+	}
+	if q.Base != "" {
+		clauses = append(clauses, "baseasset = ?")
+		args = append(args, strings.ToUpper(q.Base))
+	}
+	if q.Quote != "" {
+		clauses = append(clauses, quotecolumn+" = ?")
+		args = append(args, strings.ToUpper(q.Quote))
+	}
+	if q.Notice != "" && events {
+		clauses = append(clauses, "noticetype = ?")
+		args = append(args, strings.ToUpper(q.Notice))
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = " where " + strings.Join(clauses, " and ")
+	}
+	where += " order by timestamp"
+	if q.Limit > 0 {
+		where += " limit ? offset ?"
+		args = append(args, q.Limit, q.Offset)
+	}
+	return where, args
+}
+
+// SelectEvents - Returns the stored events matching the query
+func (s *sqlstore) SelectEvents(q Query) ([]data.EventRecord, error) {
+	events := make([]data.EventRecord, 0)
+	where, args := s.querywhere(q, "quotaasset", true)
+	rows, err := s.db.Query(s.rebind("select timestamp, eventtype, noticetype, symbol, "+
+		"baseasset, quotaasset, volume, pricechange, period, sendtimestamp, "+
+		"coalesce(details, ''), coalesce(value, 0), coalesce(ratio, 0) from events"+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ev data.EventRecord
+		var timestamp, sendtimestamp int64
+		err = rows.Scan(&timestamp,
+			&ev.EventType,
+			&ev.NoticeType,
+			&ev.Symbol,
+			&ev.BaseAsset,
+			&ev.QuotaAsset,
+			&ev.Volume,
+			&ev.PriceChange,
+			&ev.Period,
+			&sendtimestamp,
+			&ev.Details,
+			&ev.Value,
+			&ev.Ratio)
+		if err != nil {
+			return nil, err
+		}
+		ev.Timestamp = frommillis(timestamp)
+		ev.SendTimestamp = frommillis(sendtimestamp)
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// SelectTrades - Returns the stored trades matching the query
+func (s *sqlstore) SelectTrades(q Query) ([]data.TradeRecord, error) {
+	trades := make([]data.TradeRecord, 0)
+	// Trades carry no notice type, TRADE selects them explicitly
+	if q.Notice != "" && strings.ToUpper(q.Notice) != "TRADE" {
+		return trades, nil
+	}
+	where, args := s.querywhere(q, "quoteasset", false)
+	rows, err := s.db.Query(s.rebind("select timestamp, eventtype, symbol, quoteasset, "+
+		"baseasset, quantity, price, tradetimestamp, ismaker "+
+		"from trades"+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tr data.TradeRecord
+		var timestamp, tradetimestamp int64
+		err = rows.Scan(&timestamp,
+			&tr.EventType,
+			&tr.Symbol,
+			&tr.QuoteAsset,
+			&tr.BaseAsset,
+			&tr.Quantity,
+			&tr.Price,
+			&tradetimestamp,
+			&tr.IsMaker)
+		if err != nil {
+			return nil, err
+		}
+		tr.Timestamp = frommillis(timestamp)
+		tr.TradeTimestamp = frommillis(tradetimestamp)
+		trades = append(trades, tr)
+	}
+	return trades, rows.Err()
+}
@@ -0,0 +1,269 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gobit/internal/data"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Supported export formats
+const (
+	CSV      = "csv"
+	JSONL    = "jsonl"
+	Parquet  = "parquet"
+	Text     = "text"     // aligned columns, statistics and summaries only
+	Markdown = "markdown" // summaries only
+)
+
+// Parquet row layouts, timestamps are stored as epoch milliseconds
+type parquetevent struct {
+	Timestamp     int64   `parquet:"name=timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	EventType     string  `parquet:"name=eventtype, type=BYTE_ARRAY, convertedtype=UTF8"`
+	NoticeType    string  `parquet:"name=noticetype, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Symbol        string  `parquet:"name=symbol, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BaseAsset     string  `parquet:"name=baseasset, type=BYTE_ARRAY, convertedtype=UTF8"`
+	QuotaAsset    string  `parquet:"name=quoteasset, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Volume        float64 `parquet:"name=volume, type=DOUBLE"`
+	PriceChange   float64 `parquet:"name=pricechange, type=DOUBLE"`
+	Period        string  `parquet:"name=period, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SendTimestamp int64   `parquet:"name=sendtimestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Details       string  `parquet:"name=details, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Value         float64 `parquet:"name=value, type=DOUBLE"`
+	Ratio         float64 `parquet:"name=ratio, type=DOUBLE"`
+}
+
+type parquettrade struct {
+	Timestamp      int64   `parquet:"name=timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	EventType      string  `parquet:"name=eventtype, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Symbol         string  `parquet:"name=symbol, type=BYTE_ARRAY, convertedtype=UTF8"`
+	QuoteAsset     string  `parquet:"name=quoteasset, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BaseAsset      string  `parquet:"name=baseasset, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Quantity       float64 `parquet:"name=quantity, type=DOUBLE"`
+	Price          float64 `parquet:"name=price, type=DOUBLE"`
+	TradeTimestamp int64   `parquet:"name=tradetimestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	IsMaker        bool    `parquet:"name=ismaker, type=BOOLEAN"`
+}
+
+var eventheader = []string{"timestamp", "eventtype", "noticetype", "symbol",
+	"baseasset", "quoteasset", "volume", "pricechange", "period", "sendtimestamp", "details", "value", "ratio"}
+
+var tradeheader = []string{"timestamp", "eventtype", "symbol", "quoteasset",
+	"baseasset", "quantity", "price", "tradetimestamp", "ismaker"}
+
+// ValidFormat returns true when the export format is supported
+func ValidFormat(format string) bool {
+	return format == CSV || format == JSONL || format == Parquet
+}
+
+// WriteEvents - Writes the events to w in the requested format
+func WriteEvents(w io.Writer, format string, events []data.EventRecord) error {
+	switch format {
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(eventheader)
+		for _, ev := range events {
+			cw.Write([]string{
+				formattime(ev.Timestamp),
+				ev.EventType,
+				ev.NoticeType,
+				ev.Symbol,
+				ev.BaseAsset,
+				ev.QuotaAsset,
+				formatfloat(ev.Volume),
+				formatfloat(ev.PriceChange),
+				ev.Period,
+				formattime(ev.SendTimestamp),
+				ev.Details,
+				formatfloat(ev.Value),
+				formatfloat(ev.Ratio),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case JSONL:
+		enc := json.NewEncoder(w)
+		for _, ev := range events {
+			if err := enc.Encode(ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Parquet:
+		pw, err := writer.NewParquetWriterFromWriter(w, new(parquetevent), 1)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			err = pw.Write(parquetevent{
+				Timestamp:     millis(ev.Timestamp),
+				EventType:     ev.EventType,
+				NoticeType:    ev.NoticeType,
+				Symbol:        ev.Symbol,
+				BaseAsset:     ev.BaseAsset,
+				QuotaAsset:    ev.QuotaAsset,
+				Volume:        ev.Volume,
+				PriceChange:   ev.PriceChange,
+				Period:        ev.Period,
+				SendTimestamp: millis(ev.SendTimestamp),
+				Details:       ev.Details,
+				Value:         ev.Value,
+				Ratio:         ev.Ratio,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return pw.WriteStop()
+	}
+	return errors.New("unknown export format " + format)
+}
+
+// WriteTrades - Writes the trades to w in the requested format
+func WriteTrades(w io.Writer, format string, trades []data.TradeRecord) error {
+	switch format {
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(tradeheader)
+		for _, tr := range trades {
+			cw.Write([]string{
+				formattime(tr.Timestamp),
+				tr.EventType,
+				tr.Symbol,
+				tr.QuoteAsset,
+				tr.BaseAsset,
+				formatfloat(tr.Quantity),
+				formatfloat(tr.Price),
+				formattime(tr.TradeTimestamp),
+				strconv.FormatBool(tr.IsMaker),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case JSONL:
+		enc := json.NewEncoder(w)
+		for _, tr := range trades {
+			if err := enc.Encode(tr); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Parquet:
+		pw, err := writer.NewParquetWriterFromWriter(w, new(parquettrade), 1)
+		if err != nil {
+			return err
+		}
+		for _, tr := range trades {
+			err = pw.Write(parquettrade{
+				Timestamp:      millis(tr.Timestamp),
+				EventType:      tr.EventType,
+				Symbol:         tr.Symbol,
+				QuoteAsset:     tr.QuoteAsset,
+				BaseAsset:      tr.BaseAsset,
+				Quantity:       tr.Quantity,
+				Price:          tr.Price,
+				TradeTimestamp: millis(tr.TradeTimestamp),
+				IsMaker:        tr.IsMaker,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return pw.WriteStop()
+	}
+	return errors.New("unknown export format " + format)
+}
+
+// Horizon names of data.OutcomeHorizons, like 5m or 1h
+func horizons() []string {
+	names := make([]string, 0, len(data.OutcomeHorizons))
+	for _, h := range data.OutcomeHorizons {
+		name := strings.TrimSuffix(h.String(), "0s")
+		names = append(names, strings.TrimSuffix(name, "0m"))
+	}
+	return names
+}
+
+// WriteOutcomeStats - Writes the notice outcome statistics to w as text,
+// csv or jsonl, with the count, hit rate and average move of every horizon
+func WriteOutcomeStats(w io.Writer, format string, stats []data.OutcomeStat) error {
+	header := []string{"notice", "level"}
+	for _, h := range horizons() {
+		header = append(header, h+" n", h+" hit%", h+" avg%")
+	}
+	row := func(s data.OutcomeStat) []string {
+		fields := []string{s.NoticeType, s.EventType}
+		for i := range data.OutcomeHorizons {
+			fields = append(fields, strconv.Itoa(s.Count[i]),
+				fmt.Sprintf("%.1f", s.HitRate(i)),
+				fmt.Sprintf("%+.3f", s.Move[i]))
+		}
+		return fields
+	}
+
+	switch format {
+	case Text:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+		for _, s := range stats {
+			fmt.Fprintln(tw, strings.Join(row(s), "\t")+"\t")
+		}
+		return tw.Flush()
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		for _, s := range stats {
+			cw.Write(row(s))
+		}
+		cw.Flush()
+		return cw.Error()
+	case JSONL:
+		enc := json.NewEncoder(w)
+		for _, s := range stats {
+			if err := enc.Encode(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.New("unsupported statistics format " + format)
+}
+
+func formattime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatfloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,141 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package export
+
+import (
+	"errors"
+	"fmt"
+	"gobit/internal/data"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Width of the maker/taker flow bars
+const summarybarwidth = 20
+
+// WriteSummary - Writes the summary of a period as aligned text or markdown
+func WriteSummary(w io.Writer, format string, s data.Summary) error {
+	var section func(title string, header []string, rows [][]string) error
+	switch format {
+	case Text:
+		fmt.Fprintf(w, "Summary %s - %s\n%d events, %d trades\n",
+			s.From.Format("2006-01-02 15:04"), s.To.Format("2006-01-02 15:04"), s.Events, s.Trades)
+		section = func(title string, header []string, rows [][]string) error {
+			fmt.Fprintf(w, "\n%s\n", title)
+			if len(rows) == 0 {
+				fmt.Fprintln(w, "  none")
+				return nil
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "  "+strings.Join(header, "\t"))
+			for _, row := range rows {
+				fmt.Fprintln(tw, "  "+strings.Join(row, "\t"))
+			}
+			return tw.Flush()
+		}
+	case Markdown:
+		fmt.Fprintf(w, "# Summary %s - %s\n\n%d events, %d trades\n",
+			s.From.Format("2006-01-02 15:04"), s.To.Format("2006-01-02 15:04"), s.Events, s.Trades)
+		section = func(title string, header []string, rows [][]string) error {
+			fmt.Fprintf(w, "\n## %s\n\n", title)
+			if len(rows) == 0 {
+				fmt.Fprintln(w, "None")
+				return nil
+			}
+			fmt.Fprintf(w, "| %s |\n|%s\n", strings.Join(header, " | "), strings.Repeat(" --- |", len(header)))
+			for _, row := range rows {
+				fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | "))
+			}
+			return nil
+		}
+	default:
+		return errors.New("unsupported summary format " + format)
+	}
+
+	notices := make([][]string, 0, len(s.Notices))
+	for _, n := range s.Notices {
+		notices = append(notices, []string{n.NoticeType, n.EventType, strconv.Itoa(n.Count)})
+	}
+	movers := func(list []data.Mover) [][]string {
+		rows := make([][]string, 0, len(list))
+		for _, m := range list {
+			rows = append(rows, []string{m.Symbol, fmt.Sprintf("%+.2f%%", m.Change), m.Period,
+				m.Time.Format("15:04:05")})
+		}
+		return rows
+	}
+	largest := make([][]string, 0, len(s.Largest))
+	for _, t := range s.Largest {
+		side := "Taker"
+		if t.IsMaker {
+			side = "Maker"
+		}
+		largest = append(largest, []string{t.Symbol, side, fmt.Sprintf("%.6g", t.Quantity), fmt.Sprintf("%.8g", t.Price),
+			fmt.Sprintf("%.0f", t.Notional), t.Timestamp.Format("15:04:05")})
+	}
+	active := make([][]string, 0, len(s.Active))
+	for _, a := range s.Active {
+		active = append(active, []string{a.Symbol, strconv.Itoa(a.Trades),
+			fmt.Sprintf("%.0f", a.Maker), fmt.Sprintf("%.0f", a.Taker), takerpercent(a.Maker, a.Taker)})
+	}
+	flow := make([][]string, 0, len(s.Flow))
+	for _, f := range s.Flow {
+		flow = append(flow, []string{f.From.Format("01-02 15:04"), fmt.Sprintf("%.0f", f.Maker),
+			fmt.Sprintf("%.0f", f.Taker), takerpercent(f.Maker, f.Taker), flowbar(f.Maker, f.Taker)})
+	}
+
+	sections := []struct {
+		title  string
+		header []string
+		rows   [][]string
+	}{
+		{"Notices", []string{"Notice", "Level", "Count"}, notices},
+		{"Top Gainers", []string{"Pair", "Change", "Period", "Time"}, movers(s.Gainers)},
+		{"Top Losers", []string{"Pair", "Change", "Period", "Time"}, movers(s.Losers)},
+		{"Largest Trades", []string{"Pair", "Side", "Quantity", "Price", "Notional", "Time"}, largest},
+		{"Most Active Subscriptions", []string{"Pair", "Trades", "Maker", "Taker", "Taker%"}, active},
+		{"Maker/Taker Flow", []string{"From", "Maker", "Taker", "Taker%", "Taker/Maker"}, flow},
+	}
+	for _, sec := range sections {
+		if err := section(sec.title, sec.header, sec.rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// takerpercent returns the taker share of the notional
+func takerpercent(maker, taker float64) string {
+	if maker+taker == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", taker/(maker+taker)*100)
+}
+
+// flowbar returns a bar of the taker (█) and maker (░) shares
+func flowbar(maker, taker float64) string {
+	if maker+taker == 0 {
+		return ""
+	}
+	n := int(taker/(maker+taker)*summarybarwidth + 0.5)
+	return strings.Repeat("█", n) + strings.Repeat("░", summarybarwidth-n)
+}
@@ -0,0 +1,166 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package ui
+
+import (
+	"fmt"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"gobit/internal/db"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// DisplayCoMovementPage - Page with a heatmap of the base assets getting
+// same direction price change or volume notices at most a window apart
+func DisplayCoMovementPage(app *tview.Application, pages *tview.Pages, store db.Store) {
+	heatmap := tview.NewTable().
+		SetBordersColor(tcell.ColorGray).
+		SetFixed(1, 1)
+	heatmap.SetBorder(true).
+		SetTitle("Co-Movement").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+	list := tview.NewTable().
+		SetSeparator(tview.Borders.Vertical).
+		SetBordersColor(tcell.ColorGray).
+		SetFixed(1, 0)
+	list.SetBorder(true).
+		SetTitle("Strongest Pairs").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+
+	closepage := func() {
+		pages.RemovePage("comovement")
+		pages.SwitchToPage("grid")
+	}
+
+	load := func() {
+		heatmap.SetTitle("Co-Movement (loading ...)")
+		go func() {
+			events, err := store.SelectEvents(db.Query{From: time.Now().Add(-Conf.CoMovement.Period)})
+			if err != nil {
+				log.Println("Error querying co-movement events " + err.Error())
+			}
+			comovements := data.CoMovements(events, Conf.CoMovement.Window, Conf.CoMovement.MinCount)
+			app.QueueUpdateDraw(func() {
+				printheatmap(heatmap, comovements)
+				printcomovements(list, comovements)
+				heatmap.SetTitle(fmt.Sprintf("Co-Movement (%d notices, %s apart, last %s, r: reload, q: back)",
+					len(events), FormatWindow(Conf.CoMovement.Window), FormatWindow(Conf.CoMovement.Period)))
+			})
+		}()
+	}
+
+	capture := func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closepage()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if heatmap.HasFocus() {
+				app.SetFocus(list)
+			} else {
+				app.SetFocus(heatmap)
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case 'r':
+			load()
+		case 'q':
+			closepage()
+			return nil
+		}
+		return event
+	}
+	heatmap.SetInputCapture(capture)
+	list.SetInputCapture(capture)
+
+	layout := tview.NewFlex().
+		AddItem(heatmap, 0, 3, true).
+		AddItem(list, 44, 0, false)
+	pages.AddAndSwitchToPage("comovement", layout, true)
+	load()
+}
+
+// printheatmap - Fills the heatmap of the assets of the strongest pairs,
+// brighter cells moved together more often
+func printheatmap(t *tview.Table, comovements []data.CoMovement) {
+	t.Clear()
+	assets := data.CoMovementAssets(comovements, Conf.CoMovement.Assets)
+	scores := make(map[[2]string]float64)
+	for _, c := range comovements {
+		scores[[2]string{c.A, c.B}] = c.Score
+		scores[[2]string{c.B, c.A}] = c.Score
+	}
+	header := func(row, col int, text string) {
+		t.SetCell(row, col, tview.NewTableCell(text).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	header(0, 0, "")
+	for i, a := range assets {
+		header(0, i+1, a)
+		header(i+1, 0, a)
+		for j, b := range assets {
+			cell := tview.NewTableCell("·").
+				SetTextColor(tcell.ColorGray).
+				SetSelectable(false).
+				SetAlign(tview.AlignCenter)
+			if score, ok := scores[[2]string{a, b}]; ok {
+				level := int32(40 + score*200)
+				cell.SetText(fmt.Sprintf("%.2f", score)).
+					SetTextColor(tcell.ColorWhite).
+					SetBackgroundColor(tcell.NewRGBColor(level, 40, 40-int32(score*40)))
+			} else if i == j {
+				cell.SetText("")
+			}
+			t.SetCell(i+1, j+1, cell)
+		}
+	}
+	if len(assets) == 0 {
+		t.SetCell(1, 0, tview.NewTableCell(fmt.Sprintf("No assets moved together %d times yet, see CoMovement.MinCount",
+			Conf.CoMovement.MinCount)).SetSelectable(false))
+	}
+}
+
+// printcomovements - Fills the list of the strongest pairs
+func printcomovements(t *tview.Table, comovements []data.CoMovement) {
+	t.Clear()
+	for col, text := range []string{"Assets", "Together", "Moves", "Score"} {
+		t.SetCell(0, col, tview.NewTableCell(text).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for r, c := range comovements {
+		row := r + 1
+		t.SetCell(row, 0, tview.NewTableCell(c.A+" "+c.B))
+		t.SetCell(row, 1, tview.NewTableCell(strconv.Itoa(c.Together)).SetAlign(tview.AlignRight))
+		t.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf("%d/%d", c.CountA, c.CountB)).SetAlign(tview.AlignRight))
+		t.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf("%.2f", c.Score)).SetAlign(tview.AlignRight))
+	}
+}
@@ -0,0 +1,317 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package ui
+
+import (
+	"fmt"
+	"gobit/internal/binance"
+	"gobit/internal/data"
+	"gobit/internal/db"
+	"gobit/internal/util"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Rows per history page
+const historypagesize = 100
+
+// Notice types selectable in the history browser
+var historynotices = []string{"ALL", "PRICE_CHANGE", "PRICE_BREAKTHROUGH",
+	"VOLUME_PRICE", "BLOCK_TRADE", "COMPOSITE", "VOLATILITY", "INDICATOR", "NOTICE_RATE", "TRADE"}
+
+// historyrow - Stored event or trade, exactly one is set
+type historyrow struct {
+	event *data.EventRecord
+	trade *data.TradeRecord
+}
+
+// DisplayHistoryPage - Page to browse stored events and trades. Tickers and
+// symbol info are kept by the page, the live feed maps are never touched.
+func DisplayHistoryPage(app *tview.Application, pages *tview.Pages, store db.Store) {
+	page := 0
+
+	// Tickers and symbol info fetched by the searches, and the copies of the
+	// rows shown, used by the ui goroutine only
+	var cache sync.Mutex
+	tickers := make(map[string]binance.Ticker)
+	symbols := make(map[string]data.Symbol)
+	stats := make(map[string]binance.Ticker)
+	info := make(map[string]data.Symbol)
+
+	table := InitLiveFeed()
+	table.SetTitle("History")
+	detail := InitDetailsTable()
+	detail.SetText(data.Messages["history"])
+
+	form := tview.NewForm().SetHorizontal(true)
+	form.SetBorderPadding(0, 0, 1, 1).
+		SetBorder(true).
+		SetTitle("Query").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+	form.AddInputField("Symbol", "", 9, nil, nil).
+		AddInputField("Base", "", 6, nil, nil).
+		AddInputField("Quote", "", 6, nil, nil).
+		AddDropDown("Notice", historynotices, 0, nil).
+		AddInputField("From", "", 16, nil, nil).
+		AddInputField("To", "", 16, nil, nil)
+
+	closepage := func() {
+		pages.RemovePage("history")
+		pages.SwitchToPage("grid")
+	}
+
+	// Query stored rows and fill the table, network calls stay off the ui goroutine
+	search := func() {
+		q, err := historyquery(form)
+		if err != nil {
+			table.SetTitle("History (invalid time " + err.Error() + ")")
+			return
+		}
+		table.SetTitle("History (searching ...)")
+		go func(page int) {
+			rows, err := historyrows(store, q, page)
+			if err != nil {
+				log.Println("Error querying history " + err.Error())
+			}
+			cache.Lock()
+			missing := make(map[string]bool)
+			for _, r := range rows {
+				symbol := ""
+				if r.event != nil {
+					symbol = r.event.Symbol
+				} else {
+					symbol = r.trade.Symbol
+					if symbols[symbol].Symbol == "" {
+						symbols[symbol] = data.Symbol{Symbol: symbol,
+							BaseAsset:  r.trade.BaseAsset,
+							QuoteAsset: r.trade.QuoteAsset}
+					}
+				}
+				if tickers[symbol].Name == "" {
+					missing[symbol] = true
+				}
+			}
+			cache.Unlock()
+
+			// One request covers the tickers of all the missing pairs, other
+			// searches can use the cache meanwhile
+			var fetched []binance.Ticker
+			if len(missing) > 0 {
+				if fetched, err = binance.GetAllTickers(); err != nil {
+					log.Println("Error loading history tickers " + err.Error())
+				}
+			}
+
+			cache.Lock()
+			for _, ticker := range fetched {
+				if missing[ticker.Name] {
+					tickers[ticker.Name] = ticker
+				}
+			}
+			rowstats := make(map[string]binance.Ticker, len(tickers))
+			for symbol, ticker := range tickers {
+				rowstats[symbol] = ticker
+			}
+			rowinfo := make(map[string]data.Symbol, len(symbols))
+			for symbol, s := range symbols {
+				rowinfo[symbol] = s
+			}
+			cache.Unlock()
+			app.QueueUpdateDraw(func() {
+				stats, info = rowstats, rowinfo
+				table.Clear()
+				for _, r := range rows {
+					if r.event != nil {
+						PrintEvent(table, stats, recordevent(*r.event), store)
+					} else {
+						PrintTrade(table, stats, info, recordtrade(*r.trade), store, math.NaN())
+					}
+				}
+				if len(rows) == 0 {
+					printeventheader(table)
+				}
+				table.SetTitle(fmt.Sprintf("History (page %d, %d rows)", page+1, len(rows)))
+				table.ScrollToBeginning()
+			})
+		}(page)
+	}
+
+	form.AddButton("Search", func() {
+		page = 0
+		search()
+		app.SetFocus(table)
+	}).
+		AddButton("Close", closepage).
+		SetCancelFunc(closepage)
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			if r, c := table.GetSelectable(); r && c {
+				table.SetSelectable(false, false)
+			} else {
+				closepage()
+			}
+		case tcell.KeyEnter:
+			table.SetSelectable(true, true)
+		case tcell.KeyTab, tcell.KeyBacktab:
+			app.SetFocus(form)
+		}
+	})
+	table.SetSelectedFunc(func(row, column int) {
+		UpdateDetailTable(table.GetCell(row, column).Text, EventDetails(table, row), detail, stats, DetailSources{})
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'o':
+			if r, c := table.GetSelectable(); r && c {
+				row, col := table.GetSelection()
+				if row != 0 {
+					asset := strings.Replace(table.GetCell(row, col).Text, "/", "_", 1)
+					util.ShowWebTrade(asset)
+				}
+			}
+		case 'n':
+			page++
+			search()
+		case 'p':
+			if page > 0 {
+				page--
+				search()
+			}
+		case 'q':
+			closepage()
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 5, 0, true).
+		AddItem(tview.NewFlex().
+			AddItem(table, 0, 3, false).
+			AddItem(detail, 32, 0, false), 0, 1, false)
+
+	pages.AddAndSwitchToPage("history", layout, true)
+	search()
+}
+
+// historyquery - Builds the db query from the form fields
+func historyquery(form *tview.Form) (db.Query, error) {
+	var q db.Query
+	var err error
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+	q.Symbol = text("Symbol")
+	q.Base = text("Base")
+	q.Quote = text("Quote")
+	if _, notice := form.GetFormItemByLabel("Notice").(*tview.DropDown).GetCurrentOption(); notice != "ALL" {
+		q.Notice = notice
+	}
+	if q.From, err = util.ParseTime(text("From")); err != nil {
+		return q, err
+	}
+	if q.To, err = util.ParseTime(text("To")); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// historyrows - Merges events and trades by time and returns the requested page
+func historyrows(store db.Store, q db.Query, page int) ([]historyrow, error) {
+	rows := make([]historyrow, 0)
+	// Both tables are read up to the end of the page to merge them in order
+	q.Limit = (page + 1) * historypagesize
+	events, err := store.SelectEvents(q)
+	if err != nil {
+		return rows, err
+	}
+	trades, err := store.SelectTrades(q)
+	if err != nil {
+		return rows, err
+	}
+	for i := range events {
+		rows = append(rows, historyrow{event: &events[i]})
+	}
+	for i := range trades {
+		rows = append(rows, historyrow{trade: &trades[i]})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].timestamp().Before(rows[j].timestamp())
+	})
+
+	start := page * historypagesize
+	if start > len(rows) {
+		return rows[:0], nil
+	}
+	end := start + historypagesize
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[start:end], nil
+}
+
+func (r historyrow) timestamp() time.Time {
+	if r.event != nil {
+		return r.event.Timestamp
+	}
+	return r.trade.Timestamp
+}
+
+// recordevent - Converts a stored event to the websocket structure
+func recordevent(r data.EventRecord) binance.Event {
+	var ev binance.Event
+	ev.ReceiveTimestamp = uint64(r.Timestamp.UnixNano() / int64(time.Millisecond))
+	ev.Data.EventType = r.EventType
+	ev.Data.NoticeType = r.NoticeType
+	ev.Data.Symbol = r.Symbol
+	ev.Data.BaseAsset = r.BaseAsset
+	ev.Data.QuotaAsset = r.QuotaAsset
+	ev.Data.Volume = float32(r.Volume)
+	ev.Data.PriceChange = float32(r.PriceChange)
+	ev.Data.Period = r.Period
+	ev.Data.SendTimestamp = uint64(r.SendTimestamp.UnixNano() / int64(time.Millisecond))
+	ev.Data.Details = r.Details
+	ev.Data.Value = r.Value
+	ev.Data.Ratio = r.Ratio
+	return ev
+}
+
+// recordtrade - Converts a stored trade to the websocket structure
+func recordtrade(r data.TradeRecord) binance.Trade {
+	var tr binance.Trade
+	tr.ReceiveTimestamp = uint64(r.Timestamp.UnixNano() / int64(time.Millisecond))
+	tr.Data.EventType = r.EventType
+	tr.Data.Symbol = r.Symbol
+	tr.Data.Quantity = r.Quantity
+	tr.Data.Price = r.Price
+	tr.Data.TradeTimestamp = uint64(r.TradeTimestamp.UnixNano() / int64(time.Millisecond))
+	tr.Data.IsMaker = r.IsMaker
+	return tr
+}
@@ -0,0 +1,142 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package ui
+
+import (
+	"fmt"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"gobit/internal/db"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// InitVolumeProfile ui element init
+func InitVolumeProfile() *tview.TextView {
+	profile := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(false)
+	profile.SetBorder(true).SetTitle("Volume Profile").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+	profile.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		return action, nil
+	})
+	return profile
+}
+
+// ProfileRequests - Counts the volume profile requests, only the result of
+// the last one is printed
+type ProfileRequests struct {
+	last uint64
+}
+
+// Next returns the generation of a new request
+func (r *ProfileRequests) Next() uint64 {
+	return atomic.AddUint64(&r.last, 1)
+}
+
+// stale - Whether a later request was made after generation
+func (r *ProfileRequests) stale(generation uint64) bool {
+	return atomic.LoadUint64(&r.last) != generation
+}
+
+// UpdateVolumeProfile - Prints the volume at price of the stored trades of
+// symbol during the last span, one level per line from the highest price.
+// The trades are queried on the calling goroutine, call it with go and the
+// generation of requests.Next() so an older request can't print over it.
+func UpdateVolumeProfile(app *tview.Application, profile *tview.TextView, store db.Store, symbol string,
+	span time.Duration, requests *ProfileRequests, generation uint64) {
+	name := strings.Replace(symbol, "/", "", 1)
+	title := fmt.Sprintf("Volume Profile %s (%s)", name, FormatWindow(span))
+	if name == "" {
+		app.QueueUpdateDraw(func() {
+			if requests.stale(generation) {
+				return
+			}
+			profile.SetTitle(title)
+			profile.SetText(data.Messages["volumeprofile"])
+		})
+		return
+	}
+	trades, err := store.SelectTrades(db.Query{Symbol: name, From: time.Now().Add(-span)})
+	if err != nil {
+		log.Println("Error querying volume profile " + err.Error())
+		return
+	}
+	app.QueueUpdateDraw(func() {
+		if requests.stale(generation) {
+			return
+		}
+		profile.SetTitle(title)
+		_, _, width, height := profile.GetInnerRect()
+		if height < 2 {
+			height = 10
+		}
+		p := data.NewVolumeProfile(trades, height-1, Conf.Profile.ValueArea)
+		if len(p.Levels) == 0 {
+			profile.SetText("No stored trades of " + name)
+			return
+		}
+		profile.SetText(printprofile(p, width))
+	})
+}
+
+// printprofile - Builds the profile bars, buys green and sells red,
+// the point of control is marked with ◆ and the value area with │
+func printprofile(p data.VolumeProfile, width int) string {
+	maxvolume := p.Levels[p.POC].Volume()
+	labels := make([]string, len(p.Levels))
+	labelwidth := 0
+	for i, level := range p.Levels {
+		labels[i] = fmt.Sprintf("%.6g", (level.Low+level.High)/2)
+		if len(labels[i]) > labelwidth {
+			labelwidth = len(labels[i])
+		}
+	}
+	barwidth := width - labelwidth - 3
+	if barwidth < 1 {
+		barwidth = 1
+	}
+
+	text := fmt.Sprintf("[::d]%d trades, POC %s[::-]\n", p.Trades, labels[p.POC])
+	for i := len(p.Levels) - 1; i >= 0; i-- {
+		level := p.Levels[i]
+		marker := " "
+		if i == p.POC {
+			marker = "[yellow]◆[-]"
+		} else if i >= p.ValueLow && i <= p.ValueHigh {
+			marker = "│"
+		}
+		buy, sell := 0, 0
+		if maxvolume > 0 {
+			buy = int(level.Buy / maxvolume * float64(barwidth))
+			sell = int(level.Volume()/maxvolume*float64(barwidth)) - buy
+		}
+		text += fmt.Sprintf("%*s %s[green]%s[red]%s[-]\n", labelwidth, labels[i], marker,
+			strings.Repeat("█", buy), strings.Repeat("█", sell))
+	}
+	return strings.TrimSuffix(text, "\n")
+}
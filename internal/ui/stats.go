@@ -0,0 +1,153 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package ui
+
+import (
+	"fmt"
+	"gobit/internal/data"
+	"gobit/internal/db"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Outcome periods before now of the statistics page and their labels
+var statsperiods = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+var statslabels = []string{"24h", "7d", "30d"}
+
+// DisplayStatsPage - Page with the hit rate of the stored notices, how often
+// and how far the price kept moving in the signalled direction
+func DisplayStatsPage(app *tview.Application, pages *tview.Pages, store db.Store) {
+	table := tview.NewTable().
+		SetSeparator(tview.Borders.Vertical).
+		SetBordersColor(tcell.ColorGray).
+		SetFixed(2, 2)
+	table.SetBorder(true).
+		SetTitle("Notice Outcomes").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+
+	closepage := func() {
+		pages.RemovePage("stats")
+		pages.SwitchToPage("grid")
+	}
+
+	period := 0
+	load := func() {
+		table.SetTitle("Notice Outcomes (loading ...)")
+		span, label := statsperiods[period], statslabels[period]
+		go func() {
+			outcomes, err := store.SelectOutcomes(db.Query{From: time.Now().Add(-span)})
+			if err != nil {
+				log.Println("Error querying notice outcomes " + err.Error())
+			}
+			stats := data.OutcomeStats(outcomes)
+			app.QueueUpdateDraw(func() {
+				printstats(table, stats)
+				table.SetTitle(fmt.Sprintf("Notice Outcomes %s (%d notices, p: period, r: reload, q: back)",
+					label, len(outcomes)))
+			})
+		}()
+	}
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			closepage()
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'p':
+			period = (period + 1) % len(statsperiods)
+			load()
+		case 'r':
+			load()
+		case 'q':
+			closepage()
+			return nil
+		}
+		return event
+	})
+
+	pages.AddAndSwitchToPage("stats", table, true)
+	load()
+}
+
+// printstats - Fills the statistics table, hit rates over 55% are green
+// and under 45% red
+func printstats(t *tview.Table, stats []data.OutcomeStat) {
+	t.Clear()
+	header := func(row, col int, text string) {
+		t.SetCell(row, col, tview.NewTableCell(text).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	header(0, 0, "Notice")
+	header(0, 1, "Level")
+	header(1, 0, "")
+	header(1, 1, "")
+	for i, h := range data.OutcomeHorizons {
+		header(0, 2+i*3, "+"+FormatWindow(h))
+		header(0, 3+i*3, "")
+		header(0, 4+i*3, "")
+		header(1, 2+i*3, "N")
+		header(1, 3+i*3, "Hit")
+		header(1, 4+i*3, "Avg")
+	}
+
+	for r, s := range stats {
+		row := r + 2
+		t.SetCell(row, 0, tview.NewTableCell(s.NoticeType).SetSelectable(false))
+		t.SetCell(row, 1, tview.NewTableCell(s.EventType).SetSelectable(false))
+		for i := range data.OutcomeHorizons {
+			color := tcell.ColorWhite
+			hit := ""
+			move := ""
+			if s.Count[i] > 0 {
+				hit = fmt.Sprintf("%.0f%%", s.HitRate(i))
+				move = fmt.Sprintf("%+.2f%%", s.Move[i])
+				if s.HitRate(i) > 55 {
+					color = tcell.ColorGreen
+				} else if s.HitRate(i) < 45 {
+					color = tcell.ColorRed
+				}
+			}
+			t.SetCell(row, 2+i*3, tview.NewTableCell(strconv.Itoa(s.Count[i])).
+				SetSelectable(false).
+				SetAlign(tview.AlignRight))
+			t.SetCell(row, 3+i*3, tview.NewTableCell(hit).
+				SetTextColor(color).
+				SetSelectable(false).
+				SetAlign(tview.AlignRight))
+			t.SetCell(row, 4+i*3, tview.NewTableCell(move).
+				SetTextColor(color).
+				SetSelectable(false).
+				SetAlign(tview.AlignRight))
+		}
+	}
+	if len(stats) == 0 {
+		t.SetCell(2, 0, tview.NewTableCell("No notice outcomes yet, prices are recorded up to 1h after the notices").
+			SetSelectable(false))
+	}
+}
@@ -0,0 +1,180 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package ui
+
+import (
+	"fmt"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"gobit/internal/util"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// DisplayStrengthPage - Page ranking the assets of the Popularity widget by
+// their performance relative to BTC and ETH
+func DisplayStrengthPage(app *tview.Application, pages *tview.Pages, popularity *data.PopularityRanks) {
+	table := tview.NewTable().
+		SetSeparator(tview.Borders.Vertical).
+		SetBordersColor(tcell.ColorGray).
+		SetFixed(2, 2)
+	table.SetBorder(true).
+		SetTitle("Relative Strength").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+
+	closepage := func() {
+		pages.RemovePage("strength")
+		pages.SwitchToPage("grid")
+	}
+
+	// Klines and tickers are fetched off the ui goroutine
+	load := func() {
+		table.SetTitle("Relative Strength (loading ...)")
+		assets := make([]string, 0)
+		for _, a := range popularity[data.PopularityAssets].Rank(time.Now(), Conf.Popularity.Rows) {
+			if a.Name != Conf.Trades.DefaultQuote {
+				assets = append(assets, a.Name)
+			}
+		}
+		go func() {
+			benchmarks := make(map[string]data.Performance)
+			for _, asset := range []string{"BTC", "ETH"} {
+				// Failed benchmarks stay unknown, shown as -
+				p, err := util.AssetPerformance(asset)
+				if err != nil {
+					log.Println("Error fetching the performance of " + asset + " " + err.Error())
+					continue
+				}
+				benchmarks[asset] = p
+			}
+			performances := make([]data.Performance, 0, len(assets))
+			for _, asset := range assets {
+				p, err := util.AssetPerformance(asset)
+				if err != nil {
+					log.Println("Error fetching the performance of " + asset + " " + err.Error())
+					continue
+				}
+				performances = append(performances, p)
+			}
+			strengths := data.RelativeStrengths(performances, benchmarks["BTC"], benchmarks["ETH"])
+			app.QueueUpdateDraw(func() {
+				printstrengths(table, strengths)
+				table.SetTitle(fmt.Sprintf("Relative Strength vs %s (%d assets, r: reload, q: back)",
+					Conf.Trades.DefaultQuote, len(strengths)))
+			})
+		}()
+	}
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			closepage()
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'r':
+			load()
+		case 'q':
+			closepage()
+			return nil
+		}
+		return event
+	})
+
+	pages.AddAndSwitchToPage("strength", table, true)
+	load()
+}
+
+// printstrengths - Fills the relative strength table, outperformance
+// green, underperformance red and unknown benchmarks -
+func printstrengths(t *tview.Table, strengths []data.RelativeStrength) {
+	t.Clear()
+	header := func(row, col int, text string) {
+		t.SetCell(row, col, tview.NewTableCell(text).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	percent := func(row, col int, v float64) {
+		color := tcell.ColorWhite
+		if v > 0 {
+			color = tcell.ColorGreen
+		} else if v < 0 {
+			color = tcell.ColorRed
+		}
+		t.SetCell(row, col, tview.NewTableCell(fmt.Sprintf("%+.2f%%", v)).
+			SetTextColor(color).
+			SetSelectable(false).
+			SetAlign(tview.AlignRight))
+	}
+	unknown := func(row, col int) {
+		t.SetCell(row, col, tview.NewTableCell("-").
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false).
+			SetAlign(tview.AlignRight))
+	}
+	n := len(data.StrengthHorizons)
+	header(0, 0, "#")
+	header(0, 1, "Asset")
+	header(1, 0, "")
+	header(1, 1, "")
+	for g, group := range []string{"Change", "vs BTC", "vs ETH"} {
+		for i, h := range data.StrengthHorizons {
+			label := ""
+			if i == 0 {
+				label = group
+			}
+			header(0, 2+g*n+i, label)
+			header(1, 2+g*n+i, FormatWindow(h))
+		}
+	}
+	header(0, 2+3*n, "Score")
+	header(1, 2+3*n, "")
+
+	for r, s := range strengths {
+		row := r + 2
+		t.SetCell(row, 0, tview.NewTableCell(strconv.Itoa(r+1)).SetSelectable(false).SetAlign(tview.AlignRight))
+		t.SetCell(row, 1, tview.NewTableCell(s.Asset).SetSelectable(false))
+		for i := range data.StrengthHorizons {
+			percent(row, 2+i, s.Returns[i])
+			for g, vs := range [][]float64{s.VsBTC, s.VsETH} {
+				if vs == nil {
+					unknown(row, 2+(g+1)*n+i)
+				} else {
+					percent(row, 2+(g+1)*n+i, vs[i])
+				}
+			}
+		}
+		if s.VsBTC == nil && s.VsETH == nil {
+			unknown(row, 2+3*n)
+		} else {
+			percent(row, 2+3*n, s.Score)
+		}
+	}
+	if len(strengths) == 0 {
+		t.SetCell(2, 0, tview.NewTableCell("No popular assets yet, see the Popularity widget").
+			SetSelectable(false))
+	}
+}
@@ -0,0 +1,135 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	. "gobit/internal/config"
+	"gobit/internal/db"
+	"gobit/internal/export"
+	"gobit/internal/util"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Summary periods before now, zero is the session
+var summaryperiods = []time.Duration{0, time.Hour, 4 * time.Hour, 24 * time.Hour}
+
+// DisplaySummaryPage - Page with the summary of the stored events and
+// trades of the session or of the last hours
+func DisplaySummaryPage(app *tview.Application, pages *tview.Pages, store db.Store, session time.Time) {
+	period := 0
+	text := tview.NewTextView().
+		SetDynamicColors(false).
+		SetScrollable(true)
+	text.SetBorder(true).
+		SetTitle("Summary").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+
+	closepage := func() {
+		pages.RemovePage("summary")
+		pages.SwitchToPage("grid")
+	}
+
+	from := func() time.Time {
+		if summaryperiods[period] == 0 {
+			return session
+		}
+		return time.Now().Add(-summaryperiods[period])
+	}
+	label := func() string {
+		if summaryperiods[period] == 0 {
+			return "session"
+		}
+		return FormatWindow(summaryperiods[period])
+	}
+
+	load := func() {
+		text.SetTitle("Summary (loading ...)")
+		go func(from time.Time, label string) {
+			var b bytes.Buffer
+			summary, err := util.Summary(store, from, time.Time{})
+			if err == nil {
+				err = export.WriteSummary(&b, export.Text, summary)
+			}
+			if err != nil {
+				log.Println("Error building summary " + err.Error())
+			}
+			app.QueueUpdateDraw(func() {
+				text.SetText(b.String())
+				text.ScrollToBeginning()
+				text.SetTitle("Summary " + label + " (p: period, w: write markdown, r: reload, q: back)")
+			})
+		}(from(), label())
+	}
+
+	// Writes the markdown summary to the cache folder
+	write := func() {
+		go func(from time.Time) {
+			path := Storagepath + "/summary-" + time.Now().Format("20060102-150405") + ".md"
+			summary, err := util.Summary(store, from, time.Time{})
+			if err == nil {
+				var f *os.File
+				if f, err = os.Create(path); err == nil {
+					err = export.WriteSummary(f, export.Markdown, summary)
+					f.Close()
+				}
+			}
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					log.Println("Error writing summary " + err.Error())
+					text.SetTitle("Summary (error writing " + path + ")")
+					return
+				}
+				text.SetTitle(fmt.Sprintf("Summary written to %s", path))
+			})
+		}(from())
+	}
+
+	text.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			closepage()
+		}
+	})
+	text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'p':
+			period = (period + 1) % len(summaryperiods)
+			load()
+		case 'r':
+			load()
+		case 'w':
+			write()
+		case 'q':
+			closepage()
+			return nil
+		}
+		return event
+	})
+
+	pages.AddAndSwitchToPage("summary", text, true)
+	load()
+}
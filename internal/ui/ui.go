@@ -20,19 +20,19 @@
 package ui
 
 import (
-	"database/sql"
 	"fmt"
 	"gobit/internal/binance"
 	. "gobit/internal/config"
 	"gobit/internal/data"
+	"gobit/internal/db"
 	"gobit/internal/util"
+	"log"
 	"math"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
-	_ "github.com/mattn/go-sqlite3"
-
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -63,6 +63,20 @@ func InitTrendBar() *tview.TextView {
 	return trendbar
 }
 
+// InitBreadthGauge ui element init
+func InitBreadthGauge() *tview.TextView {
+	gauge := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(false)
+	gauge.SetBorder(true).SetTitle("Breadth").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderAttributes(tcell.AttrDim)
+	gauge.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		return action, nil
+	})
+	return gauge
+}
+
 // InitMomentumTable ui element init
 func InitMomentumTable() *tview.TextView {
 	momentumtable := tview.NewTextView().
@@ -98,7 +112,7 @@ func PrintMomentumTable(tablewidth int, assetstats []data.AssetStat) string {
 	var maxmomentum float64
 	var maxnamelen int
 
-	if assetstats == nil {
+	if len(assetstats) == 0 {
 		return "No enough data\n"
 	}
 
@@ -114,10 +128,10 @@ func PrintMomentumTable(tablewidth int, assetstats []data.AssetStat) string {
 
 	// Assemble Bar Graph Text
 	var padding, width int
-	var bar, label, leftline string
+	var bar, label, leftline, rank string
 
 	for _, asset := range assetstats {
-		padding = 3 + maxnamelen
+		padding = 3 + maxnamelen + 3
 		if tablewidth > 42 {
 			padding += 8
 			// Optional Volume Average
@@ -136,6 +150,9 @@ func PrintMomentumTable(tablewidth int, assetstats []data.AssetStat) string {
 			}
 			bar = strings.Repeat("▱", width-padding)
 
+			// Rank Change
+			rank = rankchange(asset)
+
 			// Left Line
 			leftline = strings.Repeat(" ", maxnamelen-len(asset.Name)) + "│"
 
@@ -144,58 +161,249 @@ func PrintMomentumTable(tablewidth int, assetstats []data.AssetStat) string {
 
 			// Assemble Graph Line
 			if tablewidth > 42 {
-				rightpadding := tablewidth - utf8.RuneCountInString(label+leftline+bar+volumeavg)
+				rightpadding := tablewidth - utf8.RuneCountInString(label+leftline+bar+volumeavg) - 3
 				rightalign = strings.Repeat(" ", rightpadding)
 			}
-			bargraph += label + leftline + bar + rightalign + volumeavg + "\n"
+			// Notice rate spikes mark the left line, after its width is counted
+			if asset.Spike {
+				leftline = strings.TrimSuffix(leftline, "│") + "[fuchsia]![-]"
+			}
+			bargraph += rank + label + leftline + bar + rightalign + volumeavg + "\n"
 		}
 	}
 	return strings.TrimSuffix(bargraph, "\n")
 }
 
-// UpdateTrendBar - prints the trend bar
-func UpdateTrendBar(width int, tradestats *data.TradeStat) string {
+// UpdateMomentumTable - Ranks every popularity view and prints the view
+// selected in the momentum table, marking base assets with notice rate spikes
+func UpdateMomentumTable(momentumtable *tview.TextView, popularity *data.PopularityRanks, rates *data.NoticeRates, view int) {
+	_, _, width, _ := momentumtable.GetInnerRect()
+	titles := [data.PopularityViews]string{"", " by Quote", " by Notice", " Bullish", " Bearish"}
+	momentumtable.SetTitle("Popularity" + titles[view] + " (" + Conf.Db.SamplePeriod + ")")
+	ranks := popularity.Rank(time.Now(), Conf.Popularity.Rows)
+	// Base assets with notice rate spikes
+	if view != data.PopularityQuotes && view != data.PopularityNotices {
+		for i := range ranks[view] {
+			ranks[view][i].Spike = rates.Spiking(ranks[view][i].Name, time.Now())
+		}
+	}
+	if text := PrintMomentumTable(width, ranks[view]); text != "" {
+		momentumtable.SetTextAlign(tview.AlignRight)
+		momentumtable.SetText(text)
+	}
+}
+
+// rankchange returns the three cells wide rank change marker of an asset
+func rankchange(asset data.AssetStat) string {
+	switch {
+	case asset.New:
+		return "[yellow] * [-]"
+	case asset.Change > 9:
+		return "[green]↑9+[-]"
+	case asset.Change > 0:
+		return fmt.Sprintf("[green]↑%-2d[-]", asset.Change)
+	case asset.Change < -9:
+		return "[red]↓9+[-]"
+	case asset.Change < 0:
+		return fmt.Sprintf("[red]↓%-2d[-]", -asset.Change)
+	}
+	return "   "
+}
+
+// Most rows of the per pair trend view
+const maxtrendrows = 8
+
+// Trade trend bar views
+const (
+	TrendAggregate = iota // all subscribed pairs
+	TrendSelected         // pair selected in the live feed
+	TrendPairs            // one bar per subscribed pair
+	TrendViews
+)
+
+// UpdateTrendBar - prints the trend bar of the trades inside window
+func UpdateTrendBar(width int, tradestats *data.TradeStat, window time.Duration) string {
+	return "\r" + trendbarline(width, tradestats.Window(time.Now(), window), window)
+}
+
+// trendbarline - Builds a single maker/taker bar of the trade flow
+func trendbarline(width int, flow data.TradeBucket, window time.Duration) string {
 	var redboxes, greenboxes string
 
 	// Calculate cells based on rounded percentage
-	if tradestats.Maker == 0 || tradestats.Taker == 0 || tradestats.Number == 0 {
-	} else {
-		trendpercent := tradestats.Maker / (tradestats.Taker + tradestats.Maker)
-		if width > 3 {
-			leftpadding := 1
-			rightpadding := 2
-			count := int(math.Floor(float64(width) * trendpercent))
-
-			redcount := 0
-			greencount := 0
-			if count == 0 {
-				leftpadding = 0
-				rightpadding = 4
-			} else if count == width {
-				leftpadding = 4
-				rightpadding = 0
-			}
+	if flow.Number == 0 {
+		return data.Messages["notenoughtrades"]
+	}
+	if flow.Maker == 0 || flow.Taker == 0 || width <= 3 {
+		return fmt.Sprintf("Not enough trades in %s", FormatWindow(window))
+	}
+	trendpercent := flow.Maker / (flow.Taker + flow.Maker)
+	leftpadding := 1
+	rightpadding := 2
+	count := int(math.Floor(float64(width) * trendpercent))
 
-			redcount = width - count - rightpadding
-			if redcount < 0 {
-				redcount = 0
-			}
-			greencount = count - leftpadding
-			greenboxes = strings.Repeat("▓", greencount)
-			redboxes = strings.Repeat("▓", redcount)
+	redcount := 0
+	greencount := 0
+	if count == 0 {
+		leftpadding = 0
+		rightpadding = 4
+	} else if count == width {
+		leftpadding = 4
+		rightpadding = 0
+	}
+
+	redcount = width - count - rightpadding
+	if redcount < 0 {
+		redcount = 0
+	}
+	greencount = count - leftpadding
+	greenboxes = strings.Repeat("▓", greencount)
+	redboxes = strings.Repeat("▓", redcount)
 
-			// Print Bar
-			if tradestats.Number >= 10 {
-				return fmt.Sprintf("\r[green]%s[white]%.0f%%[red]%s[white]", greenboxes, trendpercent*100, redboxes)
+	// Print Bar
+	if flow.Number >= 10 {
+		return fmt.Sprintf("[green]%s[white]%.0f%%[red]%s[white]", greenboxes, trendpercent*100, redboxes)
+	}
+	return fmt.Sprintf("Not enough events")
+}
+
+// TrendBarTitle - Trend bar title with window, label, covered notional and subscriptions
+func TrendBarTitle(tradestats *data.TradeStat, window time.Duration, label string, subscriptions []string) string {
+	flow := tradestats.Window(time.Now(), window)
+	if label != "" {
+		label += " "
+	}
+	title := fmt.Sprintf("Trade Trend %s %s(%s %s)", FormatWindow(window), label, FormatNotional(flow.Maker+flow.Taker), Conf.Trades.DefaultQuote)
+	if len(subscriptions) > 0 {
+		title += " (" + strings.ToUpper(strings.Join(subscriptions, " ")) + ")"
+	}
+	return title
+}
+
+// UpdateTrendView - Prints the trend bar of the view, returns the text rows it needs
+func UpdateTrendView(trendbar *tview.TextView, tradestats *data.TradeStats, view int, window time.Duration, selected string, subscriptions []string) int {
+	_, _, width, _ := trendbar.GetInnerRect()
+	trendbar.SetTextAlign(tview.AlignCenter)
+
+	switch view {
+	case TrendSelected:
+		symbol := strings.Replace(selected, "/", "", 1)
+		pair := tradestats.Pair(symbol)
+		if pair == nil {
+			trendbar.SetTitle(fmt.Sprintf("Trade Trend %s %s", FormatWindow(window), symbol))
+			trendbar.SetText("\rNo trades, select a subscribed pair in the live feed")
+			return 1
+		}
+		trendbar.SetTitle(TrendBarTitle(pair, window, symbol, nil))
+		trendbar.SetText(UpdateTrendBar(width, pair, window))
+		return 1
+	case TrendPairs:
+		symbols := tradestats.Symbols()
+		if len(symbols) == 0 {
+			trendbar.SetTitle(TrendBarTitle(tradestats.All, window, "per pair", subscriptions))
+			trendbar.SetText("\r" + data.Messages["notenoughtrades"])
+			return 1
+		}
+		if len(symbols) > maxtrendrows {
+			symbols = symbols[:maxtrendrows]
+		}
+		labelwidth := 0
+		for _, symbol := range symbols {
+			if len(symbol) > labelwidth {
+				labelwidth = len(symbol)
+			}
+		}
+		lines := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			if pair := tradestats.Pair(symbol); pair != nil {
+				label := symbol + strings.Repeat(" ", labelwidth-len(symbol))
+				// Adaptive thresholds and overrides differ per pair
+				if Conf.Trades.Adaptive.Enabled || HasOverrides() {
+					threshold, _ := pair.Limit()
+					label += fmt.Sprintf(" >%8s", FormatNotional(threshold))
+				}
+				label += "│"
+				barwidth := width - utf8.RuneCountInString(label)
+				lines = append(lines, label+trendbarline(barwidth, pair.Window(time.Now(), window), window))
 			}
-			return fmt.Sprintf("\rNot enough events")
 		}
+		trendbar.SetTitle(TrendBarTitle(tradestats.All, window, "per pair", nil))
+		trendbar.SetTextAlign(tview.AlignLeft)
+		trendbar.SetText(strings.Join(lines, "\n"))
+		return len(lines)
 	}
-	return ""
+	trendbar.SetTitle(TrendBarTitle(tradestats.All, window, "", subscriptions))
+	trendbar.SetText(UpdateTrendBar(width, tradestats.All, window))
+	return 1
 }
 
-// UpdateDetailTable - Prints the detail table based on the input symbol pair
-func UpdateDetailTable(symbol string, detail *tview.TextView, stats map[string]binance.Ticker) {
+// UpdateBreadthGauge - Prints one gauge per breadth window of the advancing
+// (green) and declining (red) symbols and the advancing percentage
+func UpdateBreadthGauge(gauge *tview.TextView, breadth *data.Breadth) {
+	_, _, width, _ := gauge.GetInnerRect()
+	segment := width / len(BreadthWindows)
+	now := time.Now()
+	line := ""
+	for i, window := range BreadthWindows {
+		advancing, declining := breadth.Count(now, window)
+		if i == 0 {
+			gauge.SetTitle(fmt.Sprintf("Breadth ▲%d ▼%d", advancing, declining))
+		}
+		label := FormatWindow(window)
+		percent := "  -"
+		barwidth := segment - len(label) - 7
+		if barwidth < 1 {
+			barwidth = 1
+		}
+		bar := "[gray]" + strings.Repeat("▱", barwidth) + "[-]"
+		if total := advancing + declining; total > 0 {
+			up := int(math.Round(float64(advancing) / float64(total) * float64(barwidth)))
+			percent = fmt.Sprintf("%3.0f", float64(advancing)/float64(total)*100)
+			bar = "[green]" + strings.Repeat("▰", up) + "[red]" + strings.Repeat("▰", barwidth-up) + "[-]"
+		}
+		line += fmt.Sprintf("%s %s %s%% ", label, bar, percent)
+	}
+	gauge.SetText(strings.TrimSuffix(line, " "))
+}
+
+// FormatWindow - Short durations, like 5m instead of 5m0s
+func FormatWindow(window time.Duration) string {
+	text := window.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// FormatNotional - Short notional amounts, like 1.25M
+func FormatNotional(amount float64) string {
+	switch {
+	case math.Abs(amount) >= 1e9:
+		return fmt.Sprintf("%.2fB", amount/1e9)
+	case math.Abs(amount) >= 1e6:
+		return fmt.Sprintf("%.2fM", amount/1e6)
+	case math.Abs(amount) >= 1e3:
+		return fmt.Sprintf("%.2fK", amount/1e3)
+	}
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// DetailSources - The live statistics shown in the detail table, nil sources
+// are left out, like on the history page where none is set
+type DetailSources struct {
+	TradeStats   *data.TradeStats   // order flow of subscribed pairs
+	Volatilities *data.Volatilities // realized volatility of subscribed pairs
+	Indicators   *data.IndicatorSet // technical indicators of watched pairs
+	Window       time.Duration      // order flow window
+}
+
+// UpdateDetailTable - Prints the detail table based on the input symbol pair,
+// with the note of the selected event, like the signals of composite events,
+// and the statistics of the pair in sources
+func UpdateDetailTable(symbol, note string, detail *tview.TextView, stats map[string]binance.Ticker, sources DetailSources) {
 	name := strings.Replace(symbol, "/", "", 1)
 	price := stats[name].LastPrice
 	volume := stats[name].Volume
@@ -203,65 +411,353 @@ func UpdateDetailTable(symbol string, detail *tview.TextView, stats map[string]b
 	lowprice := stats[name].LowPrice
 	highprice := stats[name].HighPrice
 	detail.Clear()
-	fmt.Fprintf(detail, "Symbol: %s\nPrice: %s\n24H Change: %s%%\nVolume: %s\nDaily High: %s\nDaily Low:%s",
-		name,
+	fmt.Fprintf(detail, "Symbol: %s\n", name)
+	if note != "" {
+		fmt.Fprintf(detail, "[yellow]%s[white]\n", tview.Escape(note))
+	}
+	fmt.Fprintf(detail, "Price: %s\n24H Change: %s%%\nVolume: %s\nDay Range: %s - %s",
 		strconv.FormatFloat(price, 'f', -1, 64),
 		strconv.FormatFloat(pricechange, 'f', 2, 64),
 		strconv.FormatFloat(volume, 'f', -1, 64),
-		strconv.FormatFloat(highprice, 'f', -1, 64),
-		strconv.FormatFloat(lowprice, 'f', -1, 64))
+		strconv.FormatFloat(lowprice, 'f', -1, 64),
+		strconv.FormatFloat(highprice, 'f', -1, 64))
+
+	// Technical indicators of watched pairs at the last closed kline
+	if sources.Indicators != nil {
+		if ind, ok := sources.Indicators.Pair(name); ok {
+			fmt.Fprintf(detail, "\n%s", indicatorlines(ind))
+		}
+	}
+
+	// Cumulative volume delta of subscribed pairs
+	window := sources.Window
+	if sources.TradeStats != nil {
+		if pair := sources.TradeStats.Pair(name); pair != nil {
+			_, _, width, _ := detail.GetInnerRect()
+			if width < 8 {
+				width = 8
+			}
+			flow := pair.Window(time.Now(), window)
+			fmt.Fprintf(detail, "\nCVD: %+.4g (%s %+.4g)\n%s",
+				pair.CumulativeDelta(),
+				FormatWindow(window),
+				flow.Delta,
+				Sparkline(pair.DeltaSeries(time.Now(), window, width)))
+
+			// Large trade threshold in use
+			threshold, source := pair.Limit()
+			fmt.Fprintf(detail, "\nThreshold: %s %s (%s)", FormatNotional(threshold), Conf.Trades.DefaultQuote, source)
+
+			// Session and anchored VWAP with the price distance
+			session, anchored := pair.VWAPs()
+			fmt.Fprintf(detail, "\n%s", vwapline("VWAP", session, price))
+			if anchored != nil {
+				fmt.Fprintf(detail, "\n%s", vwapline("AVWAP "+clocktime(anchored.Since), *anchored, price))
+			}
+		}
+	}
+
+	// Realized volatility cone of subscribed pairs
+	if sources.Volatilities == nil {
+		return
+	}
+	if v := sources.Volatilities.Pair(name); v != nil {
+		_, _, width, _ := detail.GetInnerRect()
+		if cones := v.Cone(VolatilityWindows); len(cones) > 0 {
+			fmt.Fprintf(detail, "\nRealized Volatility:%s", volatilitycone(cones, width))
+		}
+	}
+}
+
+// indicatorlines - The indicator values of a watched pair with the RSI
+// coloured at its levels and the MACD histogram and EMA trend by sign
+func indicatorlines(ind data.Indicators) string {
+	rsicolor := "white"
+	if ind.RSI > Conf.Indicators.RSI.Overbought {
+		rsicolor = "green"
+	} else if ind.RSI < Conf.Indicators.RSI.Oversold {
+		rsicolor = "red"
+	}
+	macdcolor := "green"
+	if ind.Histogram < 0 {
+		macdcolor = "red"
+	}
+	emacolor := "green"
+	if ind.FastEMA < ind.SlowEMA {
+		emacolor = "red"
+	}
+	return fmt.Sprintf("Indicators (%s, %s):\n RSI: [%s]%.1f[white]\n MACD: [%s]%.4g[white] / %.4g\n BB: %.6g - %.6g\n EMA%d/%d: [%s]%.6g[white] / %.6g",
+		Conf.Indicators.Interval, clocktime(ind.Time),
+		rsicolor, ind.RSI,
+		macdcolor, ind.MACD, ind.Signal,
+		ind.Lower, ind.Upper,
+		Conf.Indicators.EMA.Fast, Conf.Indicators.EMA.Slow, emacolor, ind.FastEMA, ind.SlowEMA)
+}
+
+// volatilitycone - One line per window with the 10th to 90th percentile
+// range of its realized volatility and the current one (●), on a scale
+// shared by all windows
+func volatilitycone(cones []data.VolatilityCone, width int) string {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, c := range cones {
+		low = math.Min(low, math.Min(c.Low, c.Current))
+		high = math.Max(high, math.Max(c.High, c.Current))
+	}
+	barwidth := width - 11
+	if barwidth < 5 {
+		barwidth = 5
+	}
+	position := func(v float64) int {
+		if high == low {
+			return 0
+		}
+		return int(math.Round((v - low) / (high - low) * float64(barwidth-1)))
+	}
+	text := ""
+	for _, c := range cones {
+		bar := []rune(strings.Repeat(" ", barwidth))
+		from, to := position(c.Low), position(c.High)
+		for i := from; i <= to; i++ {
+			bar[i] = '─'
+		}
+		bar[from], bar[to] = '├', '┤'
+		if from == to {
+			bar[from] = '│'
+		}
+		current := position(c.Current)
+		color := "white"
+		if c.Current > c.High {
+			color = "red"
+		} else if c.Current < c.Low {
+			color = "blue"
+		}
+		line := string(bar[:current]) + "[" + color + "]●[-]" + string(bar[current+1:])
+		text += fmt.Sprintf("\n%4s %4.0f%% %s", FormatWindow(c.Window), c.Current, line)
+	}
+	return text
+}
+
+// vwapline - VWAP, band width and distance of price from it
+func vwapline(label string, vwap data.VWAP, price float64) string {
+	if vwap.V == 0 {
+		return label + ": no trades"
+	}
+	line := fmt.Sprintf("%s: %.6g ±%.3g", label, vwap.Price(), Conf.Trades.VWAPBands*vwap.Deviation())
+	if price == 0 {
+		return line
+	}
+	line += fmt.Sprintf(" %+.2f%%", (price/vwap.Price()-1)*100)
+	if sigmas, ok := vwap.Sigmas(price); ok {
+		line += fmt.Sprintf(" %+.1fσ", sigmas)
+	}
+	return line
+}
+
+// Sparkline - Single line chart of the values, green when rising
+func Sparkline(values []float64) string {
+	ticks := []rune("▁▂▃▄▅▆▇█")
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		tick := 0
+		if high > low {
+			tick = int((v - low) / (high - low) * float64(len(ticks)-1))
+		}
+		line[i] = ticks[tick]
+	}
+	color := "[green]"
+	if values[len(values)-1] < values[0] {
+		color = "[red]"
+	}
+	return color + string(line) + "[white]"
 }
 
 // Basic Function to print the event table first row
 func printeventheader(t *tview.Table) {
 	// Print Top Row
 	row := 0
-	cell := tview.NewTableCell("Event").
+	timeheader := "Time"
+	if Conf.UTC {
+		timeheader = "Time UTC"
+	}
+	cell := tview.NewTableCell(timeheader).
 		SetTextColor(tcell.ColorYellow).
 		SetSelectable(false).
 		SetAlign(tview.AlignLeft)
 	t.SetCell(row, 0, cell)
+	// Event
+	cell = tview.NewTableCell("Event").
+		SetTextColor(tcell.ColorYellow).
+		SetSelectable(false).
+		SetAlign(tview.AlignLeft)
+	t.SetCell(row, 1, cell)
 	// Period
 	cell = tview.NewTableCell("Period").
 		SetTextColor(tcell.ColorYellow).
 		SetSelectable(false).
 		SetAlign(tview.AlignRight)
-	t.SetCell(row, 1, cell)
+	t.SetCell(row, 2, cell)
 	// Asset Pair Symbol
 	cell = tview.NewTableCell("Symbol").
 		SetTextColor(tcell.ColorYellow).
 		SetSelectable(false).
 		SetAlign(tview.AlignCenter)
-	t.SetCell(row, 2, cell)
+	t.SetCell(row, 3, cell)
 	// Amount
 	cell = tview.NewTableCell("Amount").
 		SetTextColor(tcell.ColorYellow).
 		SetSelectable(false).
 		SetAlign(tview.AlignCenter)
-	t.SetCell(row, 3, cell)
+	t.SetCell(row, 4, cell)
+	// Notional in default quote
+	cell = tview.NewTableCell("Notional").
+		SetTextColor(tcell.ColorYellow).
+		SetSelectable(false).
+		SetAlign(tview.AlignCenter)
+	t.SetCell(row, 5, cell)
 	// Percent
 	cell = tview.NewTableCell("Percent").
 		SetTextColor(tcell.ColorYellow).
 		SetSelectable(false).
 		SetAlign(tview.AlignCenter)
-	t.SetCell(row, 4, cell)
+	t.SetCell(row, 6, cell)
 	// Volume Frequency
 	cell = tview.NewTableCell("24H Change").
 		SetTextColor(tcell.ColorYellow).
 		SetSelectable(false).
 		SetAlign(tview.AlignCenter)
-	t.SetCell(row, 5, cell)
+	t.SetCell(row, 7, cell)
 	// Last Price
 	cell = tview.NewTableCell("Price").
 		SetTextColor(tcell.ColorYellow).
 		SetSelectable(false).
 		SetAlign(tview.AlignCenter)
-	t.SetCell(row, 6, cell)
+	t.SetCell(row, 8, cell)
+	// End-to-end Latency
+	cell = tview.NewTableCell("Latency").
+		SetTextColor(tcell.ColorYellow).
+		SetSelectable(false).
+		SetAlign(tview.AlignCenter)
+	t.SetCell(row, 9, cell)
+}
+
+// eventtime - Formats exchange epoch ms in local time or UTC
+func eventtime(ms uint64) string {
+	if ms == 0 {
+		return ""
+	}
+	return clocktime(time.Unix(0, int64(ms)*int64(time.Millisecond)))
+}
+
+// clocktime - Formats a time of day in local time or UTC
+func clocktime(t time.Time) string {
+	if Conf.UTC {
+		t = t.UTC()
+	}
+	return t.Format("15:04:05")
+}
+
+// EventDetails returns the explanation kept in a live feed row, if any
+func EventDetails(t *tview.Table, row int) string {
+	details, _ := t.GetCell(row, 1).GetReference().(string)
+	return details
+}
+
+// EventTime returns the event time kept in a live feed row, used as VWAP anchor
+func EventTime(t *tview.Table, row int) (time.Time, bool) {
+	ref, ok := t.GetCell(row, 0).GetReference().(time.Time)
+	return ref, ok
+}
+
+// quoterate - Rate converting quote into the default quote asset
+func quoterate(quote string, stats map[string]binance.Ticker) float64 {
+	if rate, ok := binance.Rate(quote); ok {
+		return rate
+	}
+	return util.QuoteRate(quote, stats)
+}
+
+// latency - Formats the end-to-end latency in ms
+func latency(received, sent uint64) string {
+	if received == 0 || sent == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dms", binance.Latency(received, sent))
+}
+
+// eventrow - The cells of an event table row, percent holds the trade
+// count of sweeps and price their price range
+type eventrow struct {
+	timestamp uint64
+	notice    string
+	details   string
+	period    string
+	symbol    string
+	value     string
+	notional  string
+	percent   string
+	change    string
+	price     string
+	latency   string
+	color     tcell.Style
+}
+
+// printeventrow - Appends a row to the event table, the time cell holds
+// the event time and the notice cell its details
+func printeventrow(t *tview.Table, r eventrow) {
+	printeventheader(t)
+	row := t.GetRowCount()
+	// Event Time
+	t.SetCell(row, 0, tview.NewTableCell(eventtime(r.timestamp)).
+		SetStyle(r.color).
+		SetSelectable(false).
+		SetAlign(tview.AlignLeft).
+		SetReference(time.Unix(0, int64(r.timestamp)*int64(time.Millisecond))))
+	// Event Name
+	t.SetCell(row, 1, tview.NewTableCell(r.notice).
+		SetStyle(r.color).
+		SetSelectable(false).
+		SetAlign(tview.AlignLeft).
+		SetReference(r.details))
+	// Period
+	t.SetCell(row, 2, tview.NewTableCell(r.period).
+		SetStyle(r.color).
+		SetSelectable(false).
+		SetAlign(tview.AlignCenter))
+	// Asset Pair Symbol
+	cell := tview.NewTableCell(r.symbol).
+		SetStyle(r.color).
+		SetAlign(tview.AlignLeft).
+		SetSelectable(true)
+	if Conf.EnableMouse {
+		symbol := r.symbol
+		cell.SetClickedFunc(func() bool {
+			asset := strings.Replace(symbol, "/", "_", 1)
+			util.ShowWebTrade(asset)
+			return false
+		})
+	}
+	t.SetCell(row, 3, cell)
+	// Value, Notional, Percent or Trade Count, 24H Change, Last Price or
+	// Price Range and Latency
+	for column, text := range []string{r.value, r.notional, r.percent, r.change, r.price, r.latency} {
+		t.SetCell(row, column+4, tview.NewTableCell(text).
+			SetStyle(r.color).
+			SetSelectable(false).
+			SetAlign(tview.AlignRight))
+	}
 }
 
 // PrintEvent - Prints and builds a new event in the event table
-func PrintEvent(t *tview.Table, stats map[string]binance.Ticker, ev binance.Event, db *sql.DB) {
-	var notice, symbol, period, value string
+func PrintEvent(t *tview.Table, stats map[string]binance.Ticker, ev binance.Event, store db.Store) {
+	var notice, symbol, period, value, notional string
 	var color tcell.Style
 	//volfreq := ""
 	percent := ""
@@ -349,10 +845,81 @@ func PrintEvent(t *tview.Table, stats map[string]binance.Ticker, ev binance.Even
 			notice = "Large Volume Rise"
 			color = color.Foreground(tcell.ColorGreen).Bold(true).Underline(true)
 		}
+	case "COMPOSITE":
+		symbol = ev.Data.BaseAsset + "/" + ev.Data.QuotaAsset
+		period = FormatWindow(Conf.Pump.Window)
+		value = fmt.Sprintf("%+.0f", ev.Data.Value)
+		switch ev.Data.EventType {
+		case "PUMP_SUSPECTED":
+			notice = "Pump Suspected"
+			color = color.Foreground(tcell.ColorGreen).Bold(true).Reverse(true)
+		case "DUMP_SUSPECTED":
+			notice = "Dump Suspected"
+			color = color.Foreground(tcell.ColorRed).Bold(true).Reverse(true)
+		}
+	case "VOLATILITY":
+		symbol = ev.Data.BaseAsset + "/" + ev.Data.QuotaAsset
+		period = FormatWindow(VolatilityWindows[0])
+		value = fmt.Sprintf("%.0f%%", ev.Data.Value)
+		if ev.Data.Ratio > 0 {
+			percent = fmt.Sprintf("x%.1f", ev.Data.Ratio)
+		}
+		switch ev.Data.EventType {
+		case "HIGH_VOLATILITY":
+			notice = "Vol Breakout"
+			color = color.Foreground(tcell.ColorOrange).Bold(true)
+		case "LOW_VOLATILITY":
+			notice = "Vol Squeeze"
+			color = color.Foreground(tcell.ColorSteelBlue).Bold(true)
+		}
+	case "NOTICE_RATE":
+		symbol = ev.Data.BaseAsset + "/" + ev.Data.QuotaAsset
+		period = FormatWindow(Conf.NoticeRate.Window)
+		value = fmt.Sprintf("%.1f/h", ev.Data.Value)
+		if ev.Data.Ratio > 0 {
+			percent = fmt.Sprintf("x%.1f", ev.Data.Ratio)
+		}
+		if ev.Data.EventType == "RATE_SPIKE" {
+			notice = "Notice Spike"
+			color = color.Foreground(tcell.ColorFuchsia).Bold(true)
+		}
+	case "INDICATOR":
+		symbol = ev.Data.BaseAsset + "/" + ev.Data.QuotaAsset
+		period = ev.Data.Period
+		value = fmt.Sprintf("%.6g", ev.Data.Value)
+		switch ev.Data.EventType {
+		case "RSI_OVERBOUGHT":
+			notice = "RSI Overbought"
+			color = color.Foreground(tcell.ColorGreen).Italic(true)
+		case "RSI_OVERSOLD":
+			notice = "RSI Oversold"
+			color = color.Foreground(tcell.ColorRed).Italic(true)
+		case "MACD_CROSS_UP":
+			notice = "MACD Cross Up"
+			color = color.Foreground(tcell.ColorGreen).Italic(true)
+		case "MACD_CROSS_DOWN":
+			notice = "MACD Cross Down"
+			color = color.Foreground(tcell.ColorRed).Italic(true)
+		case "BB_BREAKOUT_UP":
+			notice = "Band Breakout Up"
+			color = color.Foreground(tcell.ColorGreen).Italic(true).Underline(true)
+		case "BB_BREAKOUT_DOWN":
+			notice = "Band Breakout Down"
+			color = color.Foreground(tcell.ColorRed).Italic(true).Underline(true)
+		case "EMA_CROSS_UP":
+			notice = "EMA Cross Up"
+			color = color.Foreground(tcell.ColorGreen).Italic(true)
+		case "EMA_CROSS_DOWN":
+			notice = "EMA Cross Down"
+			color = color.Foreground(tcell.ColorRed).Italic(true)
+		}
 	case "BLOCK_TRADE":
 		baseasset := ev.Data.BaseAsset
 		symbol = baseasset + "/" + ev.Data.QuotaAsset
 		value = fmt.Sprintf("%.2f", ev.Data.Volume)
+		if amount := util.NoticeActivity(ev, stats).Notional; amount > 0 {
+			notional = FormatNotional(amount)
+		}
 		switch ev.Data.EventType {
 		case "BLOCK_TRADES_SELL":
 			notice = "Large Sell"
@@ -365,64 +932,27 @@ func PrintEvent(t *tview.Table, stats map[string]binance.Ticker, ev binance.Even
 		return
 	}
 
-	printeventheader(t)
-
-	// Print last row
-	row := t.GetRowCount()
-	// Event Name
-	cell := tview.NewTableCell(notice).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignLeft)
-	t.SetCell(row, 0, cell)
-	// Period
-	cell = tview.NewTableCell(period).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignCenter)
-	t.SetCell(row, 1, cell)
-	// Asset Pair Symbol
-	cell = tview.NewTableCell(symbol).
-		SetStyle(color).
-		SetAlign(tview.AlignLeft).
-		SetSelectable(true)
-	if Conf.EnableMouse {
-		cell.SetClickedFunc(func() bool {
-			asset := strings.Replace(symbol, "/", "_", 1)
-			util.ShowWebTrade(asset)
-			return false
-		})
-	}
-	t.SetCell(row, 2, cell)
-	// Value + Asset
-	cell = tview.NewTableCell(value).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 3, cell)
-	// Percent
-	cell = tview.NewTableCell(percent).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 4, cell)
-	// 24H Change
-	cell = tview.NewTableCell(change).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 5, cell)
-	// Last Price
-	cell = tview.NewTableCell(price).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 6, cell)
+	printeventrow(t, eventrow{
+		timestamp: ev.Data.SendTimestamp,
+		notice:    notice,
+		details:   ev.Data.Details,
+		period:    period,
+		symbol:    symbol,
+		value:     value,
+		notional:  notional,
+		percent:   percent,
+		change:    change,
+		price:     price,
+		latency:   latency(ev.ReceiveTimestamp, ev.Data.SendTimestamp),
+		color:     color,
+	})
 }
 
-// PrintTrade - Prints and builds a new trade in the event table
-func PrintTrade(t *tview.Table, stats map[string]binance.Ticker, info map[string]data.Symbol, tr binance.Trade, db *sql.DB) {
-	var notice, symbol, period, value, price string
+// PrintTrade - Prints and builds a new trade in the event table,
+// sigmas is the distance from the pair VWAP, NaN when unknown, trades outside
+// the bands are highlighted
+func PrintTrade(t *tview.Table, stats map[string]binance.Ticker, info map[string]data.Symbol, tr binance.Trade, store db.Store, sigmas float64) {
+	var notice, symbol, period, value, price, notional string
 	var color tcell.Style
 	percent := ""
 
@@ -438,6 +968,7 @@ func PrintTrade(t *tview.Table, stats map[string]binance.Ticker, info map[string
 	case "aggTrade":
 		value = fmt.Sprintf("%.2f", tr.Data.Quantity)
 		price = fmt.Sprintf("%v", strconv.FormatFloat(tr.Data.Price, 'f', -1, 64))
+		notional = FormatNotional(tr.Data.Price * tr.Data.Quantity * quoterate(info[tr.Data.Symbol].QuoteAsset, stats))
 		switch tr.Data.IsMaker {
 		case true:
 			notice = "Large Maker"
@@ -446,62 +977,68 @@ func PrintTrade(t *tview.Table, stats map[string]binance.Ticker, info map[string
 			notice = "Large Taker"
 			color = color.Foreground(tcell.ColorBlue)
 		}
+		if !math.IsNaN(sigmas) {
+			percent = fmt.Sprintf("%+.1fσ", sigmas)
+		}
+		if Conf.Trades.VWAPBands > 0 && math.Abs(sigmas) >= Conf.Trades.VWAPBands {
+			color = color.Reverse(true)
+		}
 	default:
 		return
 	}
 
-	printeventheader(t)
-	// Print last row
-	row := t.GetRowCount()
-	// Event Name
-	cell := tview.NewTableCell(notice).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignLeft)
-	t.SetCell(row, 0, cell)
-	// Period
-	cell = tview.NewTableCell(period).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignCenter)
-	t.SetCell(row, 1, cell)
-	// Asset Pair Symbol
-	cell = tview.NewTableCell(symbol).
-		SetStyle(color).
-		SetAlign(tview.AlignLeft).
-		SetSelectable(true)
-	if Conf.EnableMouse {
-		cell.SetClickedFunc(func() bool {
-			asset := strings.Replace(symbol, "/", "_", 1)
-			util.ShowWebTrade(asset)
-			return false
-		})
+	printeventrow(t, eventrow{
+		timestamp: tr.Data.TradeTimestamp,
+		notice:    notice,
+		period:    period,
+		symbol:    symbol,
+		value:     value,
+		notional:  notional,
+		percent:   percent,
+		change:    change,
+		price:     price,
+		latency:   latency(tr.ReceiveTimestamp, tr.Data.TradeTimestamp),
+		color:     color,
+	})
+}
+
+// PrintSweep - Prints a whale sweep, same side trades of an order split
+// across the book, in the event table
+func PrintSweep(t *tview.Table, stats map[string]binance.Ticker, info map[string]data.Symbol, sweep data.Sweep, notional float64) {
+	var notice, symbol, period, value, price, count string
+	var color tcell.Style
+
+	pricechange := stats[sweep.Symbol].PriceChangePercent24h
+	change := fmt.Sprintf("%v %%", strconv.FormatFloat(pricechange, 'f', 2, 64))
+	symbol = info[sweep.Symbol].BaseAsset + "/" + info[sweep.Symbol].QuoteAsset
+	period = fmt.Sprintf("%dms", sweep.Last-sweep.First)
+	value = fmt.Sprintf("%.2f", sweep.Quantity)
+	count = fmt.Sprintf("x%d", sweep.Count)
+	price = strconv.FormatFloat(sweep.Low, 'f', -1, 64)
+	if sweep.High != sweep.Low {
+		price += "-" + strconv.FormatFloat(sweep.High, 'f', -1, 64)
 	}
-	t.SetCell(row, 2, cell)
-	// Value + Asset
-	cell = tview.NewTableCell(value).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 3, cell)
-	// Percent
-	cell = tview.NewTableCell(percent).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 4, cell)
-	// 24H Change
-	cell = tview.NewTableCell(change).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 5, cell)
-	// Last Price
-	cell = tview.NewTableCell(price).
-		SetStyle(color).
-		SetSelectable(false).
-		SetAlign(tview.AlignRight)
-	t.SetCell(row, 6, cell)
+	// A maker buyer means the seller swept the book
+	notice = "Whale Sweep"
+	if sweep.IsMaker {
+		color = color.Foreground(tcell.ColorRed).Bold(true)
+	} else {
+		color = color.Foreground(tcell.ColorGreen).Bold(true)
+	}
+
+	printeventrow(t, eventrow{
+		timestamp: sweep.First,
+		notice:    notice,
+		period:    period,
+		symbol:    symbol,
+		value:     value,
+		notional:  FormatNotional(notional),
+		percent:   count,
+		change:    change,
+		price:     price,
+		latency:   latency(sweep.Received, sweep.Last),
+		color:     color,
+	})
 }
 
 // DisplaySubscribeModal - Modal to subscribe to trades
@@ -572,6 +1109,84 @@ func DisplaySubscribeInputForm(tx chan<- binance.SubChannelMsg, pages *tview.Pag
 	pages.AddPage("subscribeinput", form, false, true)
 }
 
+// DisplayThresholdForm - Input form to edit the threshold overrides of
+// pairs or quote assets (*QUOTE), saved to config.json
+func DisplayThresholdForm(pages *tview.Pages, s string) {
+	overrides := tview.NewTextView()
+	listoverrides := func() {
+		text := ""
+		for _, key := range OverrideKeys() {
+			threshold, _ := Override(key)
+			text += key + ": " + strconv.FormatFloat(threshold, 'f', -1, 64) + "\n"
+		}
+		if text == "" {
+			text = "No overrides, thresholds in quote asset"
+		}
+		overrides.SetText(strings.TrimSuffix(text, "\n"))
+	}
+	listoverrides()
+
+	key := strings.Replace(s, "/", "", 1)
+	value := ""
+	if threshold, ok := Override(key); ok {
+		value = strconv.FormatFloat(threshold, 'f', -1, 64)
+	}
+
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle("Threshold overrides").
+		SetTitleAlign(tview.AlignLeft)
+	save := func(remove bool) {
+		key := strings.ToUpper(form.GetFormItemByLabel("Pair or *Quote").(*tview.InputField).GetText())
+		if key == "" || key == "*" {
+			return
+		}
+		if remove {
+			DeleteOverride(key)
+		} else {
+			threshold, err := strconv.ParseFloat(form.GetFormItemByLabel("Threshold").(*tview.InputField).GetText(), 64)
+			if err != nil || threshold <= 0 {
+				form.SetTitle("Invalid threshold")
+				return
+			}
+			SetOverride(key, threshold)
+		}
+		if err := SaveOverrides(); err != nil {
+			log.Println("Error saving threshold overrides " + err.Error())
+			form.SetTitle("Error saving " + Configpath)
+			return
+		}
+		form.SetTitle("Saved to " + Configpath)
+		listoverrides()
+	}
+	form.AddInputField("Pair or *Quote", key, 14, nil, nil).
+		AddInputField("Threshold", value, 14, func(text string, ch rune) bool {
+			return strings.ContainsRune("0123456789.", ch)
+		}, nil).
+		AddButton("Save", func() {
+			save(false)
+		}).
+		AddButton("Remove", func() {
+			save(true)
+		}).
+		AddButton("Close", func() {
+			pages.RemovePage("thresholdform")
+		}).
+		SetCancelFunc(func() {
+			pages.RemovePage("thresholdform")
+		})
+
+	overrides.SetBorder(true).SetTitle("Overrides").SetTitleAlign(tview.AlignLeft)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 9, 0, true).
+		AddItem(overrides, 0, 1, false)
+
+	_, _, screenwidth, screenheight := pages.GetInnerRect()
+	textwidth, textheight := 40, 16 // Hardcoded size for now
+	layout.SetRect((screenwidth-textwidth-2)/2, (screenheight-textheight-2)/2, textwidth+2, textheight+2)
+	pages.AddPage("thresholdform", layout, false, true)
+}
+
 // DisplayUnSubscribeModal - Modal to unsubscribe to selected trades
 func DisplayUnSubscribeModal(tx chan<- binance.SubChannelMsg, pages *tview.Pages, s string) {
 	modal := tview.NewModal().
@@ -0,0 +1,109 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"gobit/internal/binance"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"log"
+	"time"
+)
+
+// Klines fetched per computation, enough to settle the moving averages
+const indicatorklines = 200
+
+// IndicatorParams returns the configured indicator periods and levels
+func IndicatorParams() data.IndicatorParams {
+	return data.IndicatorParams{
+		RSIPeriod:      Conf.Indicators.RSI.Period,
+		Overbought:     Conf.Indicators.RSI.Overbought,
+		Oversold:       Conf.Indicators.RSI.Oversold,
+		MACDFast:       Conf.Indicators.MACD.Fast,
+		MACDSlow:       Conf.Indicators.MACD.Slow,
+		MACDSignal:     Conf.Indicators.MACD.Signal,
+		BollingerLen:   Conf.Indicators.Bollinger.Period,
+		BollingerWidth: Conf.Indicators.Bollinger.Width,
+		EMAFast:        Conf.Indicators.EMA.Fast,
+		EMASlow:        Conf.Indicators.EMA.Slow,
+	}
+}
+
+// WatchIndicators - Computes the indicators of the watchlist on every poll
+// and sends the signals of newly closed klines to out. Signals are not sent
+// for the first computation of a symbol, its crossing may be long past.
+func WatchIndicators(set *data.IndicatorSet, out chan<- binance.Event) {
+	p := IndicatorParams()
+	symbols := make(map[string]data.Symbol)
+	for {
+		for _, symbol := range Conf.Indicators.Watchlist {
+			if _, ok := symbols[symbol]; !ok {
+				if err := binance.GetSymbolInfo(symbol, symbols); err != nil {
+					log.Println("Error getting symbol info of " + symbol + " " + err.Error())
+					continue
+				}
+			}
+			klines, err := binance.GetKlines(symbol, Conf.Indicators.Interval, 0, indicatorklines)
+			if err != nil {
+				log.Println("Error fetching klines of " + symbol + " " + err.Error())
+				continue
+			}
+			// The last kline is still open
+			now := binance.Now()
+			if len(klines) > 0 && klines[len(klines)-1].CloseTime > now {
+				klines = klines[:len(klines)-1]
+			}
+			times := make([]time.Time, len(klines))
+			closes := make([]float64, len(klines))
+			for i, k := range klines {
+				times[i] = time.Unix(0, int64(k.CloseTime)*int64(time.Millisecond))
+				closes[i] = k.Close
+			}
+			previous, last, ok := data.ComputeIndicators(times, closes, p)
+			if !ok {
+				continue
+			}
+			kept, seen := set.Set(symbol, last)
+			if !seen || !last.Time.After(kept.Time) {
+				continue
+			}
+			for _, signal := range data.IndicatorSignals(previous, last, p) {
+				out <- IndicatorNotice(symbols[symbol], signal, last, p)
+			}
+		}
+		time.Sleep(Conf.Indicators.Poll)
+	}
+}
+
+// IndicatorNotice returns the event of an indicator signal of a pair
+func IndicatorNotice(symbol data.Symbol, signal data.IndicatorSignal, ind data.Indicators, p data.IndicatorParams) binance.Event {
+	var ev binance.Event
+	ev.ReceiveTimestamp = binance.Now()
+	ev.Data.NoticeType = "INDICATOR"
+	ev.Data.EventType = signal.EventType
+	ev.Data.Symbol = symbol.Symbol
+	ev.Data.BaseAsset = symbol.BaseAsset
+	ev.Data.QuotaAsset = symbol.QuoteAsset
+	ev.Data.Period = Conf.Indicators.Interval
+	ev.Data.Value = signal.Value
+	ev.Data.SendTimestamp = ev.ReceiveTimestamp
+	ev.Data.Details = signal.Label + ": " + ind.Explain(p)
+	return ev
+}
@@ -0,0 +1,80 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"fmt"
+	"gobit/internal/binance"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Smoothed end-to-end latency per stream in ms
+var latencies = struct {
+	sync.Mutex
+	streams map[string]float64
+}{streams: make(map[string]float64)}
+
+// TrackLatency ...
+// Updates the moving average latency of a stream
+func TrackLatency(stream string, received, sent uint64) {
+	if received == 0 || sent == 0 {
+		return
+	}
+	latency := float64(binance.Latency(received, sent))
+	latencies.Lock()
+	defer latencies.Unlock()
+	if average, ok := latencies.streams[stream]; ok {
+		latencies.streams[stream] = average + (latency-average)/10
+	} else {
+		latencies.streams[stream] = latency
+	}
+}
+
+// ForgetLatency ...
+// Removes streams that are no longer received
+func ForgetLatency(keep func(stream string) bool) {
+	latencies.Lock()
+	defer latencies.Unlock()
+	for stream := range latencies.streams {
+		if !keep(stream) {
+			delete(latencies.streams, stream)
+		}
+	}
+}
+
+// LatencySummary returns string
+// Clock offset and latency of every stream, formatted for widget titles
+func LatencySummary() string {
+	latencies.Lock()
+	defer latencies.Unlock()
+	streams := make([]string, 0, len(latencies.streams))
+	for stream := range latencies.streams {
+		streams = append(streams, stream)
+	}
+	sort.Strings(streams)
+
+	summary := []string{fmt.Sprintf("clock %+dms", binance.ClockOffset())}
+	for _, stream := range streams {
+		summary = append(summary, fmt.Sprintf("%s %.0fms", stream, latencies.streams[stream]))
+	}
+	return strings.Join(summary, ", ")
+}
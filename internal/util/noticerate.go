@@ -0,0 +1,89 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"fmt"
+	"gobit/internal/binance"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"gobit/internal/db"
+	"log"
+	"time"
+)
+
+// NoticeBaseline - Sets the notice rate baselines from the exchange notices
+// stored over the last NoticeRate.Baseline, covering from the first one up to
+// the current window, so a spike doesn't raise its own baseline
+func NoticeBaseline(r *data.NoticeRates, store db.Store) {
+	now := time.Now()
+	end := now.Add(-Conf.NoticeRate.Window)
+	events, err := store.SelectEvents(db.Query{From: now.Add(-Conf.NoticeRate.Baseline), To: end})
+	if err != nil {
+		log.Println("Error loading notice baseline " + err.Error())
+		return
+	}
+	counts := make(map[string]int)
+	first := end
+	for _, e := range events {
+		if !data.RateNotice(e.NoticeType) {
+			continue
+		}
+		counts[e.BaseAsset]++
+		if e.Timestamp.Before(first) {
+			first = e.Timestamp
+		}
+	}
+	r.SetBaseline(counts, end.Sub(first))
+}
+
+// SeedNoticeRates - Sets the baselines and adds the stored notices of the
+// last window, so rates carry on after a restart
+func SeedNoticeRates(r *data.NoticeRates, store db.Store) {
+	NoticeBaseline(r, store)
+	events, err := store.SelectEvents(db.Query{From: time.Now().Add(-Conf.NoticeRate.Window)})
+	if err != nil {
+		log.Println("Error seeding notice rates " + err.Error())
+		return
+	}
+	for _, e := range events {
+		if data.RateNotice(e.NoticeType) {
+			r.Add(e.Timestamp, data.Symbol{Symbol: e.Symbol, BaseAsset: e.BaseAsset, QuoteAsset: e.QuotaAsset})
+		}
+	}
+}
+
+// NoticeRateEvent returns the event of a notice rate spike of a base asset,
+// on the pair of its last notice
+func NoticeRateEvent(rate data.NoticeRate) binance.Event {
+	var ev binance.Event
+	ev.ReceiveTimestamp = binance.Now()
+	ev.Data.NoticeType = "NOTICE_RATE"
+	ev.Data.EventType = "RATE_SPIKE"
+	ev.Data.Symbol = rate.Symbol.Symbol
+	ev.Data.BaseAsset = rate.Symbol.BaseAsset
+	ev.Data.QuotaAsset = rate.Symbol.QuoteAsset
+	ev.Data.Value = rate.Rate
+	ev.Data.Ratio = rate.Multiple()
+	ev.Data.SendTimestamp = ev.ReceiveTimestamp
+	ev.Data.Details = fmt.Sprintf("%d notices of %s, %.1f/h against a baseline of %.2f/h",
+		rate.Count, rate.Asset, rate.Rate, rate.Baseline)
+	return ev
+}
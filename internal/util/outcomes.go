@@ -0,0 +1,136 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"gobit/internal/binance"
+	"gobit/internal/data"
+	"gobit/internal/db"
+	"log"
+	"sort"
+	"time"
+)
+
+// Outcomes still unknown this long after the notice are given up
+const outcomepatience = 3 * time.Hour
+
+// Pairs whose klines failed are requested again after
+const outcomebackoff = 10 * time.Minute
+
+// Most klines of one request
+const outcomeklines = 1000
+
+// Pairs with failed kline requests and when to retry them, used by the
+// outcomes goroutine only
+var outcomeretry = make(map[string]time.Time)
+
+// UpdateOutcomes returns error
+// Fills the prices at and after the notices from 1m klines, with one request
+// per pair covering all its due notices. A pair is only requested when one
+// of its horizons has passed.
+func UpdateOutcomes(store db.Store) error {
+	now := time.Now()
+	pending, err := store.PendingOutcomes(now.Add(-outcomepatience))
+	if err != nil {
+		return err
+	}
+	symbols := make([]string, 0)
+	due := make(map[string][]data.Outcome)
+	for _, o := range pending {
+		if !outcomedue(o, now) {
+			continue
+		}
+		if retry, ok := outcomeretry[o.Symbol]; ok && now.Before(retry) {
+			continue
+		}
+		if _, ok := due[o.Symbol]; !ok {
+			symbols = append(symbols, o.Symbol)
+		}
+		due[o.Symbol] = append(due[o.Symbol], o)
+	}
+
+	last := data.OutcomeHorizons[len(data.OutcomeHorizons)-1]
+	for _, symbol := range symbols {
+		// Pending outcomes come in time order
+		outcomes := due[symbol]
+		start := outcomes[0].Timestamp.Truncate(time.Minute)
+		end := outcomes[len(outcomes)-1].Timestamp.Add(last)
+		if end.After(now) {
+			end = now
+		}
+		limit := int(end.Sub(start)/time.Minute) + 1
+		if limit > outcomeklines {
+			limit = outcomeklines
+		}
+		klines, err := binance.GetKlines(symbol, "1m", uint64(start.UnixNano()/int64(time.Millisecond)), limit)
+		if err != nil {
+			log.Println("Error getting klines of " + symbol + " " + err.Error())
+			outcomeretry[symbol] = now.Add(outcomebackoff)
+			continue
+		}
+		delete(outcomeretry, symbol)
+		for _, o := range outcomes {
+			if o.Price == 0 {
+				o.Price = klineprice(klines, o.Timestamp)
+			}
+			for i, horizon := range data.OutcomeHorizons {
+				if o.Prices[i] == 0 {
+					o.Prices[i] = klineprice(klines, o.Timestamp.Add(horizon))
+				}
+			}
+			if err = store.UpdateOutcome(o); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// outcomedue - Whether a price of the outcome is unknown and its time passed
+func outcomedue(o data.Outcome, now time.Time) bool {
+	if o.Price == 0 {
+		return true
+	}
+	for i, horizon := range data.OutcomeHorizons {
+		if o.Prices[i] == 0 && !o.Timestamp.Add(horizon).After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// klineprice - Price at t, the open of the 1m kline containing t so the
+// price is not taken after t. Minutes missing from the klines keep the
+// previous close, zero while no kline of t has opened yet.
+func klineprice(klines []binance.Kline, t time.Time) float64 {
+	minute := uint64(t.Truncate(time.Minute).UnixNano() / int64(time.Millisecond))
+	i := sort.Search(len(klines), func(i int) bool {
+		return klines[i].OpenTime >= minute
+	})
+	switch {
+	case i == len(klines):
+		return 0
+	case klines[i].OpenTime == minute:
+		return klines[i].Open
+	case i > 0:
+		return klines[i-1].Close
+	}
+	return klines[i].Open
+}
@@ -0,0 +1,95 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"gobit/internal/binance"
+	"gobit/internal/data"
+	"testing"
+	"time"
+)
+
+func TestKlinePrice(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	ms := func(minutes int) uint64 {
+		return uint64(start.Add(time.Duration(minutes)*time.Minute).UnixNano() / int64(time.Millisecond))
+	}
+	// Minute 2 has no trades
+	klines := []binance.Kline{
+		{OpenTime: ms(0), Open: 10, Close: 11},
+		{OpenTime: ms(1), Open: 11, Close: 12},
+		{OpenTime: ms(3), Open: 13, Close: 14},
+	}
+	tests := []struct {
+		name   string
+		klines []binance.Kline
+		at     time.Time
+		want   float64
+	}{
+		{name: "open of the minute", klines: klines, at: start.Add(time.Minute), want: 11},
+		{name: "inside the minute", klines: klines, at: start.Add(90 * time.Second), want: 11},
+		{name: "missing minute", klines: klines, at: start.Add(2*time.Minute + 30*time.Second), want: 12},
+		{name: "last kline", klines: klines, at: start.Add(3*time.Minute + 59*time.Second), want: 13},
+		{name: "not opened yet", klines: klines, at: start.Add(4 * time.Minute), want: 0},
+		{name: "before the first kline", klines: klines[2:], at: start.Add(time.Minute), want: 13},
+		{name: "no klines", at: start, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := klineprice(tt.klines, tt.at); got != tt.want {
+				t.Errorf("klineprice(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutcomeDue(t *testing.T) {
+	notice := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	known := func(n int) []float64 {
+		prices := make([]float64, len(data.OutcomeHorizons))
+		for i := 0; i < n; i++ {
+			prices[i] = 1
+		}
+		return prices
+	}
+	tests := []struct {
+		name    string
+		outcome data.Outcome
+		now     time.Time
+		want    bool
+	}{
+		{name: "no notice price", outcome: data.Outcome{Timestamp: notice, Prices: known(0)}, now: notice, want: true},
+		{name: "before the first horizon", outcome: data.Outcome{Timestamp: notice, Price: 1, Prices: known(0)},
+			now: notice.Add(59 * time.Second)},
+		{name: "first horizon", outcome: data.Outcome{Timestamp: notice, Price: 1, Prices: known(0)},
+			now: notice.Add(time.Minute), want: true},
+		{name: "known up to now", outcome: data.Outcome{Timestamp: notice, Price: 1, Prices: known(2)},
+			now: notice.Add(10 * time.Minute)},
+		{name: "all known", outcome: data.Outcome{Timestamp: notice, Price: 1, Prices: known(len(data.OutcomeHorizons))},
+			now: notice.Add(24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outcomedue(tt.outcome, tt.now); got != tt.want {
+				t.Errorf("outcomedue = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
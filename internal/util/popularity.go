@@ -0,0 +1,125 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"gobit/internal/binance"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"gobit/internal/db"
+	"log"
+	"time"
+)
+
+// PopularityWeight returns the configured popularity weight of a notice
+// type, of large trades (TRADE) or of whale sweeps (SWEEP)
+func PopularityWeight(kind string) float64 {
+	weights := Conf.Popularity.Weights
+	switch kind {
+	case "PRICE_CHANGE":
+		return weights.PriceChange
+	case "PRICE_BREAKTHROUGH":
+		return weights.PriceBreakthrough
+	case "VOLUME_PRICE":
+		return weights.VolumePrice
+	case "BLOCK_TRADE":
+		return weights.BlockTrade
+	case "COMPOSITE":
+		return weights.Composite
+	case "TRADE":
+		return weights.Trade
+	case "SWEEP":
+		return weights.Sweep
+	}
+	return 0
+}
+
+// NoticeActivity returns the popularity activity of a notice, block trades
+// carry their amount and notional
+func NoticeActivity(ev binance.Event, stats map[string]binance.Ticker) data.Activity {
+	a := data.Activity{
+		Base:      ev.Data.BaseAsset,
+		Quote:     ev.Data.QuotaAsset,
+		Kind:      ev.Data.NoticeType,
+		Direction: data.Direction(ev.Data.EventType),
+		Weight:    PopularityWeight(ev.Data.NoticeType),
+	}
+	if ev.Data.NoticeType == "BLOCK_TRADE" {
+		a.Amount = float64(ev.Data.Volume)
+		if price := stats[ev.Data.Symbol].LastPrice; price > 0 {
+			a.Notional = a.Amount * price * Rate(a.Quote, stats)
+		}
+	}
+	return a
+}
+
+// TradeActivity returns the popularity activity of a large trade or sweep
+// of symbol, notional in default quote
+func TradeActivity(symbol data.Symbol, sweep bool, ismaker bool, quantity, notional float64) data.Activity {
+	a := data.Activity{
+		Base:      symbol.BaseAsset,
+		Quote:     symbol.QuoteAsset,
+		Kind:      "TRADE",
+		Direction: 1,
+		Amount:    quantity,
+		Notional:  notional,
+	}
+	if sweep {
+		a.Kind = "SWEEP"
+	}
+	// A maker buyer means the seller was the aggressor
+	if ismaker {
+		a.Direction = -1
+	}
+	a.Weight = PopularityWeight(a.Kind)
+	return a
+}
+
+// SeedPopularity - Adds the stored notices and large trades that have not
+// yet decayed, so the rankings survive restarts
+func SeedPopularity(r *data.PopularityRanks, store db.Store) {
+	q := db.Query{From: time.Now().Add(-3 * Interval(Conf.Db.SamplePeriod))}
+	events, err := store.SelectEvents(q)
+	if err != nil {
+		log.Println("Error seeding popularity " + err.Error())
+	}
+	for _, e := range events {
+		var ev binance.Event
+		ev.Data.NoticeType = e.NoticeType
+		ev.Data.EventType = e.EventType
+		ev.Data.BaseAsset = e.BaseAsset
+		ev.Data.QuotaAsset = e.QuotaAsset
+		ev.Data.Volume = float32(e.Volume)
+		r.Add(NoticeActivity(ev, nil), e.Timestamp)
+	}
+	trades, err := store.SelectTrades(q)
+	if err != nil {
+		log.Println("Error seeding popularity " + err.Error())
+	}
+	for _, t := range trades {
+		// Rates are not loaded yet at startup
+		notional := 0.0
+		if rate, ok := binance.Rate(t.QuoteAsset); ok {
+			notional = t.Price * t.Quantity * rate
+		}
+		symbol := data.Symbol{Symbol: t.Symbol, BaseAsset: t.BaseAsset, QuoteAsset: t.QuoteAsset}
+		r.Add(TradeActivity(symbol, false, t.IsMaker, t.Quantity, notional), t.Timestamp)
+	}
+}
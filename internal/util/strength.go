@@ -0,0 +1,75 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"errors"
+	"gobit/internal/binance"
+	. "gobit/internal/config"
+	"gobit/internal/data"
+	"time"
+)
+
+// Interval of the klines of the performance
+const strengthinterval = 5 * time.Minute
+
+// AssetPerformance returns the performance of asset against the default
+// quote from five minute klines, the ticker for the 24h horizon. The default
+// quote itself does not move, on errors the returns are unknown (nil).
+func AssetPerformance(asset string) (data.Performance, error) {
+	p := data.Performance{Asset: asset}
+	if asset == Conf.Trades.DefaultQuote {
+		p.Returns = make([]float64, len(data.StrengthHorizons))
+		return p, nil
+	}
+	symbol := asset + Conf.Trades.DefaultQuote
+	span := time.Duration(0)
+	for _, h := range data.StrengthHorizons {
+		if h > span {
+			span = h
+		}
+	}
+	klines, err := binance.GetKlines(symbol, "5m", 0, int(span/strengthinterval)+1)
+	if err != nil {
+		return p, err
+	}
+	if len(klines) < 2 {
+		return p, errors.New("no klines of " + symbol)
+	}
+	last := klines[len(klines)-1].Close
+	p.Returns = make([]float64, len(data.StrengthHorizons))
+	for i, h := range data.StrengthHorizons {
+		if h == 24*time.Hour {
+			if ticker := binance.GetSymbolTicker(symbol); ticker.Name != "" {
+				p.Returns[i] = ticker.PriceChangePercent24h
+				continue
+			}
+		}
+		// The close of the kline ending h before the current one
+		k := len(klines) - 1 - int(h/strengthinterval)
+		if k < 0 {
+			k = 0
+		}
+		if klines[k].Close > 0 {
+			p.Returns[i] = (last/klines[k].Close - 1) * 100
+		}
+	}
+	return p, nil
+}
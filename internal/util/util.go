@@ -20,13 +20,16 @@
 package util
 
 import (
-	"database/sql"
 	"encoding/json"
+	"fmt"
 	"gobit/internal/binance"
 	. "gobit/internal/config"
 	"gobit/internal/data"
+	"gobit/internal/db"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/browser"
 )
@@ -40,27 +43,14 @@ type Filter struct {
 // FillSymbolStats ...
 // This function gets called periodically to find all relevant symbol pairs
 // and update them
-func FillSymbolStats(stats map[string]binance.Ticker, db *sql.DB) {
+func FillSymbolStats(stats map[string]binance.Ticker, store db.Store) {
 	// Get distinct pairs
-	query := "select distinct(symbol) from " +
-		"(select symbol,timestamp from events union " +
-		"select symbol,timestamp from trades) " +
-		"where datetime(timestamp) >= datetime('now','-" +
-		Conf.Db.SamplePeriod + "')"
-	rows, err := db.Query(query)
-	if err == sql.ErrNoRows {
-		return
-	} else if err != nil {
+	pairs, err := store.Symbols()
+	if err != nil {
 		log.Println("Error executing FillSymbolStats query " + err.Error())
-		return
 	}
 
-	for rows.Next() {
-		var pair string
-		err = rows.Scan(&pair)
-		if err != nil {
-			log.Printf("Error Fetching pairs \n")
-		}
+	for _, pair := range pairs {
 		stats[pair] = binance.GetSymbolTicker(pair)
 	}
 }
@@ -142,12 +132,73 @@ func FilterEvent(ev binance.Event, f Filter) bool {
 	return false
 }
 
+// Fewest trades of a symbol before its adaptive threshold is used
+const minadaptivesamples = 100
+
+// Threshold returns the large trade threshold of symbol in default quote
+// and its source. Overrides of the symbol or of its quote asset (*QUOTE),
+// in quote asset terms, come first. Otherwise the fixed Threshhold or the
+// adaptive percentile or z-score of the symbol trades, kept between the
+// adaptive floor and ceiling. Rate converts the quote to the default quote.
+func Threshold(symbol, quote string, rate float64, tradestats *data.TradeStats) (float64, string) {
+	if override, ok := Override(symbol); ok {
+		return override * rate, symbol
+	}
+	if override, ok := Override("*" + quote); ok {
+		return override * rate, "*" + quote
+	}
+	if !Conf.Trades.Adaptive.Enabled {
+		return Conf.Trades.Threshhold, "fixed"
+	}
+	adaptive := Conf.Trades.Adaptive
+	threshold, source := Conf.Trades.Threshhold, "fixed"
+	if pair := tradestats.Pair(symbol); pair != nil {
+		if t, samples := pair.NotionalThreshold(adaptive.Percentile, adaptive.ZScore); samples >= minadaptivesamples {
+			threshold = t
+			if adaptive.Percentile > 0 {
+				source = "p" + strconv.FormatFloat(adaptive.Percentile, 'f', -1, 64)
+			} else {
+				source = "z" + strconv.FormatFloat(adaptive.ZScore, 'f', -1, 64)
+			}
+		}
+	}
+	if adaptive.Floor > 0 && threshold < adaptive.Floor {
+		return adaptive.Floor, "floor"
+	}
+	if adaptive.Ceiling > 0 && threshold > adaptive.Ceiling {
+		return adaptive.Ceiling, "ceiling"
+	}
+	return threshold, source
+}
+
+// QuoteRate returns the rate converting quote into the default quote through
+// the direct pair, used until the conversion rates are loaded, 1 when missing
+func QuoteRate(quote string, stats map[string]binance.Ticker) float64 {
+	if quote == Conf.Trades.DefaultQuote {
+		return 1
+	}
+	if stats[quote+Conf.Trades.DefaultQuote].LastPrice == 0 {
+		stats[quote+Conf.Trades.DefaultQuote] = binance.GetSymbolTicker(quote + Conf.Trades.DefaultQuote)
+	}
+	if stats[quote+Conf.Trades.DefaultQuote].LastPrice > 0 {
+		return stats[quote+Conf.Trades.DefaultQuote].LastPrice
+	}
+	return 1
+}
+
+// Rate returns the rate converting quote into the default quote through the
+// conversion graph, or through the direct pair until the graph is loaded
+func Rate(quote string, stats map[string]binance.Ticker) float64 {
+	rate, ok := binance.Rate(quote)
+	if !ok {
+		rate = QuoteRate(quote, stats)
+	}
+	return rate
+}
+
 // FilterTrade returns boolean
 // Filter Trade streams based on a price threshhold
-func FilterTrade(tr binance.Trade, info map[string]data.Symbol, stats map[string]binance.Ticker, tradestats *data.TradeStat) bool {
-	// Price Threshhold
-	threshhold := Conf.Trades.Threshhold
-	var pricelimit float64
+func FilterTrade(tr binance.Trade, info map[string]data.Symbol, stats map[string]binance.Ticker, tradestats *data.TradeStats) bool {
 	if info[tr.Data.Symbol].Symbol == "" || stats[tr.Data.Symbol].Name == "" {
 		stats[tr.Data.Symbol] = binance.GetSymbolTicker(tr.Data.Symbol)
 		err := binance.GetSymbolInfo(tr.Data.Symbol, info)
@@ -156,34 +207,18 @@ func FilterTrade(tr binance.Trade, info map[string]data.Symbol, stats map[string
 		}
 	}
 
-	// Convert price limit to default quote asset
+	// Update Trade Stats with the notional in default quote, before the
+	// threshold so adaptive thresholds include the trade
 	quote := info[tr.Data.Symbol].QuoteAsset
-	if quote != Conf.Trades.DefaultQuote {
-		if stats[quote+Conf.Trades.DefaultQuote].LastPrice == 0 {
-			stats[quote+Conf.Trades.DefaultQuote] = binance.GetSymbolTicker(quote + Conf.Trades.DefaultQuote)
-		}
-		if stats[quote+Conf.Trades.DefaultQuote].LastPrice > 0 {
-			pricelimit = threshhold / stats[quote+Conf.Trades.DefaultQuote].LastPrice
-		} else {
-			pricelimit = threshhold
-		}
-
-	} else {
-		pricelimit = threshhold
-	}
-
-	// Update Trade Stats
-	if tradestats.Number%1000 == 0 {
-		tradestats.Maker = tradestats.Maker / 1000
-		tradestats.Taker = tradestats.Taker / 1000
-	}
+	rate := Rate(quote, stats)
+	tradestats.Add(tr.Data.Symbol, time.Now(), tr.Data.IsMaker, tr.Data.Price, tr.Data.Quantity, rate)
 
-	if tr.Data.IsMaker {
-		tradestats.Maker += tr.Data.Quantity * tr.Data.Price * pricelimit / threshhold
-	} else {
-		tradestats.Taker += tr.Data.Quantity * tr.Data.Price * pricelimit / threshhold
+	// Convert price limit to default quote asset
+	threshhold, source := Threshold(tr.Data.Symbol, quote, rate, tradestats)
+	pricelimit := threshhold / rate
+	if pair := tradestats.Pair(tr.Data.Symbol); pair != nil {
+		pair.SetLimit(threshhold, source)
 	}
-	tradestats.Number++
 
 	// Check if trade is over the quota amount limit
 	if tr.Data.Price*tr.Data.Quantity >= pricelimit {
@@ -195,6 +230,159 @@ func FilterTrade(tr binance.Trade, info map[string]data.Symbol, stats map[string
 	return false
 }
 
+// FilterSweep returns the sweep notional in default quote and true when a
+// sweep of more than one trade crosses the large trade threshold
+func FilterSweep(sweep *data.Sweep, info map[string]data.Symbol, stats map[string]binance.Ticker, tradestats *data.TradeStats) (float64, bool) {
+	if sweep == nil || sweep.Count < 2 || info[sweep.Symbol].Symbol == "" {
+		return 0, false
+	}
+	quote := info[sweep.Symbol].QuoteAsset
+	rate := Rate(quote, stats)
+	threshhold, _ := Threshold(sweep.Symbol, quote, rate, tradestats)
+	notional := sweep.Amount * rate
+	if notional < threshhold {
+		return notional, false
+	}
+	if Conf.DisableLogging == false {
+		log.Println(threshhold, *sweep)
+	}
+	return notional, true
+}
+
+// NoticeSignal returns the composite signal of a notice
+// Price changes, breakthroughs, volume moves and block trades score by level
+func NoticeSignal(ev binance.Event) (data.Signal, bool) {
+	s := data.Signal{
+		Time:  time.Unix(0, int64(ev.Data.SendTimestamp)*int64(time.Millisecond)),
+		Kind:  ev.Data.NoticeType,
+		Quote: ev.Data.QuotaAsset,
+	}
+	if ev.Data.SendTimestamp == 0 {
+		s.Time = time.Now()
+	}
+	level := func(eventtype string) float64 {
+		n, err := strconv.Atoi(eventtype[strings.LastIndex(eventtype, "_")+1:])
+		if err != nil {
+			return 1
+		}
+		return float64(n)
+	}
+	switch ev.Data.NoticeType {
+	case "PRICE_CHANGE":
+		s.Score = level(ev.Data.EventType)
+		s.Label = "Price Change " + ev.Data.EventType
+		if strings.HasPrefix(ev.Data.EventType, "DOWN") {
+			s.Score = -s.Score
+		}
+	case "PRICE_BREAKTHROUGH":
+		s.Score, s.Label = 2, "Price High"
+		if ev.Data.EventType == "DOWN_BREAKTHROUGH" {
+			s.Score, s.Label = -2, "Price Low"
+		}
+	case "VOLUME_PRICE":
+		// Volume confirms the move, one more than the level
+		s.Score = level(ev.Data.EventType) + 1
+		s.Label = "Large Volume Rise"
+		if strings.Contains(ev.Data.EventType, "DROP") {
+			s.Score, s.Label = -s.Score, "Large Volume Fall"
+		}
+	case "BLOCK_TRADE":
+		s.Score, s.Label = 1, "Large Buy"
+		if ev.Data.EventType == "BLOCK_TRADES_SELL" {
+			s.Score, s.Label = -1, "Large Sell"
+		}
+	default:
+		return s, false
+	}
+	return s, true
+}
+
+// TradeSignal returns the composite signal of a large trade or sweep,
+// an aggressive buyer is bullish
+func TradeSignal(ismaker bool, sweep bool, quote string) data.Signal {
+	s := data.Signal{Time: time.Now(), Kind: "TRADE", Score: 1, Label: "Large Taker", Quote: quote}
+	if sweep {
+		s.Score, s.Label = 2, "Whale Sweep"
+	}
+	if ismaker {
+		s.Score = -s.Score
+		if !sweep {
+			s.Label = "Large Maker"
+		}
+	}
+	return s
+}
+
+// CompositeNotice returns the event of a suspected pump or dump
+func CompositeNotice(c data.CompositeEvent) binance.Event {
+	var ev binance.Event
+	ev.ReceiveTimestamp = binance.Now()
+	ev.Data.NoticeType = "COMPOSITE"
+	ev.Data.EventType = "DUMP_SUSPECTED"
+	if c.Pump() {
+		ev.Data.EventType = "PUMP_SUSPECTED"
+	}
+	ev.Data.Symbol = c.Base + c.Quote
+	ev.Data.BaseAsset = c.Base
+	ev.Data.QuotaAsset = c.Quote
+	ev.Data.Value = c.Score
+	ev.Data.SendTimestamp = ev.ReceiveTimestamp
+	ev.Data.Details = c.Explain()
+	return ev
+}
+
+// SeedBreadth - Adds the stored notices of the longest breadth window
+func SeedBreadth(b *data.Breadth, store db.Store) {
+	events, err := store.SelectEvents(db.Query{From: time.Now().Add(-BreadthSpan())})
+	if err != nil {
+		log.Println("Error seeding breadth " + err.Error())
+	}
+	for _, e := range events {
+		b.Add(e.Timestamp, e.Symbol, data.BreadthNotice(e.NoticeType, e.EventType))
+	}
+}
+
+// Summary returns the summary of the stored events and trades between from
+// and to, notionals use the loaded conversion rates or stay in quote assets
+func Summary(store db.Store, from, to time.Time) (data.Summary, error) {
+	q := db.Query{From: from, To: to}
+	events, err := store.SelectEvents(q)
+	if err != nil {
+		return data.Summary{}, err
+	}
+	trades, err := store.SelectTrades(q)
+	if err != nil {
+		return data.Summary{}, err
+	}
+	rate := func(quote string) float64 {
+		if r, ok := binance.Rate(quote); ok {
+			return r
+		}
+		return 1
+	}
+	return data.NewSummary(from, to, events, trades, rate), nil
+}
+
+// ParseTime returns time and error
+// Parses absolute times or durations before now, empty input is zero time
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			d = -d
+		}
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q", s)
+}
+
 func ShowWebTrade(asset string) {
 	browser.OpenURL(Conf.BinanceTerminal + asset)
 }
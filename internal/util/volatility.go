@@ -0,0 +1,69 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package util
+
+import (
+	"fmt"
+	"gobit/internal/binance"
+	"gobit/internal/data"
+	"log"
+	"time"
+)
+
+// LoadVolatility - Adds the one minute klines of symbol before its first
+// trades, so the volatility cone is known after subscribing
+func LoadVolatility(v *data.Volatility, symbol string) {
+	klines, err := binance.GetKlines(symbol, "1m", 0, data.VolatilityMinutes)
+	if err != nil {
+		log.Println("Error fetching klines of " + symbol + " " + err.Error())
+		return
+	}
+	if len(klines) == 0 {
+		return
+	}
+	closes := make([]float64, len(klines))
+	for i, k := range klines {
+		closes[i] = k.Close
+	}
+	v.Seed(time.Unix(0, int64(klines[0].OpenTime)*int64(time.Millisecond)), closes)
+}
+
+// VolatilityNotice returns the event of a volatility regime change of a pair,
+// a breakout above or a squeeze below the range of the shortest window
+func VolatilityNotice(symbol data.Symbol, cone data.VolatilityCone, regime int) binance.Event {
+	var ev binance.Event
+	ev.ReceiveTimestamp = binance.Now()
+	ev.Data.NoticeType = "VOLATILITY"
+	ev.Data.EventType = "HIGH_VOLATILITY"
+	if regime < 0 {
+		ev.Data.EventType = "LOW_VOLATILITY"
+	}
+	ev.Data.Symbol = symbol.Symbol
+	ev.Data.BaseAsset = symbol.BaseAsset
+	ev.Data.QuotaAsset = symbol.QuoteAsset
+	ev.Data.Value = cone.Current
+	if cone.Median > 0 {
+		ev.Data.Ratio = cone.Current / cone.Median
+	}
+	ev.Data.SendTimestamp = ev.ReceiveTimestamp
+	ev.Data.Details = fmt.Sprintf("RV %.0f%%, range %.0f%% - %.0f%%, median %.0f%%",
+		cone.Current, cone.Low, cone.High, cone.Median)
+	return ev
+}
@@ -0,0 +1,53 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package main
+
+import (
+	"fmt"
+	"gobit/internal/data"
+	"gobit/internal/export"
+	"os"
+)
+
+// statscmd - gobit stats subcommand, hit rates of the stored notices
+func statscmd(args []string) int {
+	c := newquerycmd("stats", "24h", "1h")
+	format := c.flags.String("format", export.Text, "output format: text, csv or jsonl")
+	c.filterflags("notice type, e.g. PRICE_CHANGE")
+	if !c.parse(args) {
+		return 2
+	}
+
+	eventdb, ok := c.open()
+	if !ok {
+		return 1
+	}
+	defer eventdb.Close()
+
+	outcomes, err := eventdb.SelectOutcomes(c.query)
+	if err == nil {
+		err = export.WriteOutcomeStats(os.Stdout, *format, data.OutcomeStats(outcomes))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error computing statistics "+err.Error())
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+/*
+	Binance Intelligence Terminal in Go
+    Copyright (C) <2021-2023> <infl00p Labs>
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    any later version.
+
+    This program is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+*/
+
+package main
+
+import (
+	"fmt"
+	"gobit/internal/binance"
+	"gobit/internal/export"
+	"gobit/internal/util"
+	"os"
+)
+
+// summarycmd - gobit summary subcommand, overview of the stored events and
+// trades of a period
+func summarycmd(args []string) int {
+	c := newquerycmd("summary", "24h", "1h")
+	format := c.flags.String("format", export.Text, "output format: text or markdown")
+	c.outputflag()
+	if !c.parse(args) {
+		return 2
+	}
+
+	eventdb, ok := c.open()
+	if !ok {
+		return 1
+	}
+	defer eventdb.Close()
+
+	// Trade notionals are converted with the current rates
+	if err := binance.UpdateRates(); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: unable to load conversion rates, notionals are in quote assets")
+	}
+
+	w, closer, ok := c.writer()
+	if !ok {
+		return 1
+	}
+	defer closer()
+
+	summary, err := util.Summary(eventdb, c.query.From, c.query.To)
+	if err == nil {
+		err = export.WriteSummary(w, *format, summary)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing summary "+err.Error())
+		return 1
+	}
+	return 0
+}